@@ -17,6 +17,8 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
 )
 
 // SecretStoreRef defines which SecretStore to fetch the ExternalSecret data.
@@ -64,6 +66,54 @@ type ExternalSecretTemplate struct {
 
 	// +optional
 	Data map[string][]byte `json:"data,omitempty"`
+
+	// TemplateFrom references templates stored outside of the ExternalSecret.
+	// They are rendered the same way as the inline templates in Data.
+	// +optional
+	TemplateFrom []TemplateFrom `json:"templateFrom,omitempty"`
+}
+
+// TemplateFromUsage defines how the keys referenced by TemplateFrom are used.
+// +kubebuilder:validation:Enum=Template;Values
+type TemplateFromUsage string
+
+const (
+	// TemplateFromUsageTemplate uses every referenced key as template for the Secret key with the same name.
+	TemplateFromUsageTemplate TemplateFromUsage = "Template"
+
+	// TemplateFromUsageValues makes the referenced keys available to the templates like Provider data.
+	TemplateFromUsageValues TemplateFromUsage = "Values"
+)
+
+// TemplateFrom specifies a source of templates or template values for the Secret blueprint.
+type TemplateFrom struct {
+	// ConfigMap references a ConfigMap in the ExternalSecret namespace.
+	// +optional
+	ConfigMap *TemplateRef `json:"configMap,omitempty"`
+
+	// Secret references a Secret in the ExternalSecret namespace.
+	// +optional
+	Secret *TemplateRef `json:"secret,omitempty"`
+
+	// Usage defines whether the referenced keys hold templates for the Secret keys
+	// with the same name or values that are available to the templates.
+	// Defaults to 'Template'
+	// +optional
+	Usage TemplateFromUsage `json:"usage,omitempty"`
+}
+
+// TemplateRef references keys of a Kubernetes resource holding templates.
+type TemplateRef struct {
+	// Name of the referenced resource
+	Name string `json:"name"`
+
+	// Items lists the keys that are used as templates
+	Items []TemplateRefItem `json:"items"`
+}
+
+// TemplateRefItem selects a single key of a referenced resource.
+type TemplateRefItem struct {
+	Key string `json:"key"`
 }
 
 // ExternalSecretTarget defines the Kubernetes Secret to be created
@@ -83,6 +133,69 @@ type ExternalSecretTarget struct {
 	// Template defines a blueprint for the created Secret resource.
 	// +optional
 	Template *ExternalSecretTemplate `json:"template,omitempty"`
+
+	// Immutable marks the created Secret as immutable.
+	// The immutability of an existing Secret can not be changed, if it differs
+	// the Secret is deleted and created again. Immutable Secrets and their copies
+	// are also deleted and created again when their data changes
+	// +optional
+	Immutable bool `json:"immutable,omitempty"`
+
+	// DigestKeys additionally writes the hex encoded SHA-256 digest of every value
+	// to a key with the suffix .sha256, e.g. password.sha256 for password.
+	// Only Opaque secrets get digest keys, typed secrets like kubernetes.io/tls are left as they are
+	// +optional
+	DigestKeys bool `json:"digestKeys,omitempty"`
+
+	// ShellEnvKey additionally writes all keys of the Secret as shell variable
+	// assignments KEY='value' to this key, one per line and sorted by key, so the
+	// Secret can be sourced by shell scripts. Values are single-quoted for POSIX shells.
+	// All keys must be valid shell variable names
+	// +optional
+	ShellEnvKey string `json:"shellEnvKey,omitempty"`
+
+	// Assemble concatenates the values of multiple Provider secrets into a single Secret key.
+	// This allows to reassemble values that were split into chunks because of Provider size limits
+	// +optional
+	Assemble []ExternalSecretAssembly `json:"assemble,omitempty"`
+
+	// Compose builds a JSON document from multiple Provider values and stores it under a single Secret key.
+	// This allows to rebuild a smaller JSON object from selected properties of a larger secret
+	// +optional
+	Compose []ExternalSecretComposition `json:"compose,omitempty"`
+
+	// Namespaces lists additional namespaces the Secret is copied to.
+	// Every namespace must be allowed by the operator, otherwise the Secret is not synced.
+	// Copies are deleted when they are removed from the list or the ExternalSecret is deleted
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// ExternalSecretAssembly defines a Secret key whose value is assembled from multiple Provider values.
+type ExternalSecretAssembly struct {
+	SecretKey string `json:"secretKey"`
+
+	// RemoteRefs are fetched and concatenated in the specified order.
+	// Every chunk must exist, otherwise the Secret is not synced
+	RemoteRefs []ExternalSecretDataRemoteRef `json:"remoteRefs"`
+}
+
+// ExternalSecretComposition defines a Secret key that holds a JSON document composed from multiple Provider values.
+type ExternalSecretComposition struct {
+	SecretKey string `json:"secretKey"`
+
+	// Fields are fetched and written to their path in the JSON document.
+	// Every field must exist, otherwise the Secret is not synced
+	Fields []ExternalSecretCompositionField `json:"fields"`
+}
+
+// ExternalSecretCompositionField places a Provider value in a composed JSON document.
+type ExternalSecretCompositionField struct {
+	// Path of the value in the JSON document. Nested objects are separated by dots, e.g. db.user.
+	// A path must not be used twice or be the parent of another path
+	Path string `json:"path"`
+
+	RemoteRef ExternalSecretDataRemoteRef `json:"remoteRef"`
 }
 
 // ExternalSecretData defines the connection between the Kubernetes Secret key (spec.data.<key>) and the Provider data.
@@ -90,13 +203,35 @@ type ExternalSecretData struct {
 	SecretKey string `json:"secretKey"`
 
 	RemoteRef ExternalSecretDataRemoteRef `json:"remoteRef"`
+
+	// Optional skips the Secret key if the value can not be fetched from the Provider
+	// instead of failing the sync. Skipped keys are listed in status.skippedKeys
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+
+	// Default is used as value of the Secret key if remoteRef.property does not exist
+	// in the Provider secret. Other errors, e.g. a secret that is no valid JSON, still fail the sync
+	// +optional
+	Default *string `json:"default,omitempty"`
+
+	// SecretStoreRef overrides spec.secretStoreRef for this entry,
+	// e.g. to assemble one Secret from keys of different Providers
+	// +optional
+	SecretStoreRef *SecretStoreRef `json:"secretStoreRef,omitempty"`
 }
 
 // ExternalSecretDataRemoteRef defines Provider data location.
 type ExternalSecretDataRemoteRef struct {
-	// Key is the key used in the Provider, mandatory
+	// Key is the key used in the Provider, mandatory unless KeyFrom is set
+	// +optional
 	Key string `json:"key"`
 
+	// KeyFrom reads the key used in the Provider from a ConfigMap or Secret
+	// in the ExternalSecret namespace, e.g. to switch the Provider key during
+	// a migration. It takes precedence over Key
+	// +optional
+	KeyFrom *RemoteKeySource `json:"keyFrom,omitempty"`
+
 	// Used to select a specific version of the Provider value, if supported
 	// +optional
 	Version string `json:"version,omitempty"`
@@ -104,20 +239,285 @@ type ExternalSecretDataRemoteRef struct {
 	// +optional
 	// Used to select a specific property of the Provider value (if a map), if supported
 	Property string `json:"property,omitempty"`
+
+	// PropertyEngine selects the syntax of Property. Defaults to gjson
+	// +optional
+	PropertyEngine PropertyEngine `json:"propertyEngine,omitempty"`
+
+	// ContentType overrides the content type reported by the Provider.
+	// Properties can only be extracted from and dataFrom can only be used with application/json values,
+	// other values are always stored as they are
+	// +optional
+	ContentType ContentType `json:"contentType,omitempty"`
+
+	// MapFormat is only used with dataFrom. It selects how the Provider value is parsed into Secret keys.
+	// Defaults to json. properties reads key=value lines, keys below a [section] header are prefixed with "section."
+	// +optional
+	MapFormat MapFormat `json:"mapFormat,omitempty"`
+
+	// MapKeyField is only used with dataFrom. It reads the Provider value as JSON array of objects
+	// and stores every object under the value of this field. Duplicate values are rejected
+	// +optional
+	MapKeyField string `json:"mapKeyField,omitempty"`
+
+	// UnwrapJSONString is only used with dataFrom and the json map format. If the Provider value
+	// is a JSON document that was encoded as JSON string once more, e.g. "{\"foo\":\"bar\"}",
+	// the string is decoded before the value is parsed. Only one level is unwrapped,
+	// values that are still a JSON string afterwards are rejected. Supported by the AWS providers
+	// +optional
+	UnwrapJSONString bool `json:"unwrapJSONString,omitempty"`
+
+	// Decrypt decrypts the fetched value before it is stored, e.g. to keep values
+	// encrypted in the Provider and only decrypt them in the cluster.
+	// Only age is supported, sops files are not decrypted.
+	// Not supported with dataFrom
+	// +optional
+	Decrypt *RemoteRefDecryption `json:"decrypt,omitempty"`
+}
+
+// RemoteRefDecryption configures how a fetched value is decrypted.
+type RemoteRefDecryption struct {
+	// Age decrypts values encrypted with age (https://age-encryption.org) for X25519 recipients.
+	// Binary and ASCII armored values are supported
+	Age *AgeDecryption `json:"age"`
+}
+
+// AgeDecryption selects the age identities used for decryption.
+// Exactly one of IdentitySecretRef and IdentityRemoteRef has to be set.
+// The identities are read in the format of age key files: one AGE-SECRET-KEY-1 identity
+// per line, empty lines and # comments are ignored.
+type AgeDecryption struct {
+	// IdentitySecretRef selects a key of a Secret in the ExternalSecret namespace holding the identities
+	// +optional
+	IdentitySecretRef *esmeta.SecretKeySelector `json:"identitySecretRef,omitempty"`
+
+	// IdentityRemoteRef fetches the identities from the Provider of the store
+	// +optional
+	IdentityRemoteRef *AgeIdentityRemoteRef `json:"identityRemoteRef,omitempty"`
+}
+
+// AgeIdentityRemoteRef is the Provider value holding age identities.
+type AgeIdentityRemoteRef struct {
+	// Key is the key used in the Provider
+	Key string `json:"key"`
+
+	// Used to select a specific version of the Provider value, if supported
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Used to select a specific property of the Provider value (if a map), if supported
+	// +optional
+	Property string `json:"property,omitempty"`
+}
+
+// RemoteKeySource selects the ConfigMap or Secret key holding the key used in the Provider.
+// Exactly one of them has to be set. Surrounding whitespace of the value is ignored.
+type RemoteKeySource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap in the ExternalSecret namespace
+	// +optional
+	ConfigMapKeyRef *esmeta.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects a key of a Secret in the ExternalSecret namespace
+	// +optional
+	SecretKeyRef *esmeta.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// ContentType describes how a Provider value is interpreted.
+// +kubebuilder:validation:Enum=application/json;text/plain;application/octet-stream
+type ContentType string
+
+const (
+	// ContentTypeJSON marks a value that holds a JSON document.
+	ContentTypeJSON ContentType = "application/json"
+
+	// ContentTypeText marks a plain text value.
+	ContentTypeText ContentType = "text/plain"
+
+	// ContentTypeBinary marks a binary value.
+	ContentTypeBinary ContentType = "application/octet-stream"
+)
+
+// RefreshFailurePolicy defines how the target Secret is handled when a refresh fails.
+// +kubebuilder:validation:Enum=Retain;Strict
+type RefreshFailurePolicy string
+
+const (
+	// RefreshFailurePolicyRetain keeps the last successfully synced data in the Secret.
+	RefreshFailurePolicyRetain RefreshFailurePolicy = "Retain"
+
+	// RefreshFailurePolicyStrict also keeps the Secret, but fails the reconcile,
+	// so the refresh is retried with exponential backoff.
+	RefreshFailurePolicyStrict RefreshFailurePolicy = "Strict"
+)
+
+// SyncPolicy defines whether the target Secret is synced on every refresh or only once.
+// +kubebuilder:validation:Enum=Always;Once
+type SyncPolicy string
+
+const (
+	// SyncPolicyAlways fetches and writes the Secret on every refresh.
+	SyncPolicyAlways SyncPolicy = "Always"
+
+	// SyncPolicyOnce writes the Secret once, afterwards it is managed manually.
+	SyncPolicyOnce SyncPolicy = "Once"
+)
+
+// MapFormat is the format of a Provider value that is used with dataFrom.
+// +kubebuilder:validation:Enum=json;properties
+type MapFormat string
+
+const (
+	// MapFormatJSON parses the value as flat JSON object.
+	MapFormatJSON MapFormat = "json"
+
+	// MapFormatProperties parses the value as properties or INI file.
+	MapFormatProperties MapFormat = "properties"
+)
+
+// PropertyEngine is the syntax used to extract a property from a JSON Provider value.
+// +kubebuilder:validation:Enum=gjson;jsonpath;jq
+type PropertyEngine string
+
+const (
+	// PropertyEngineGJSON resolves properties with gjson syntax, see https://github.com/tidwall/gjson/blob/master/SYNTAX.md.
+	PropertyEngineGJSON PropertyEngine = "gjson"
+
+	// PropertyEngineJSONPath resolves properties with the JSONPath syntax of kubectl.
+	PropertyEngineJSONPath PropertyEngine = "jsonpath"
+
+	// PropertyEngineJQ resolves properties with jq filters, see https://github.com/itchyny/gojq.
+	PropertyEngineJQ PropertyEngine = "jq"
+)
+
+// KeyTransform changes the keys of the Provider data before they are written to the Secret.
+// +kubebuilder:validation:Enum=toUpper;toLower;camelToSnake
+type KeyTransform string
+
+const (
+	// KeyTransformToUpper converts keys to upper case.
+	KeyTransformToUpper KeyTransform = "toUpper"
+
+	// KeyTransformToLower converts keys to lower case.
+	KeyTransformToLower KeyTransform = "toLower"
+
+	// KeyTransformCamelToSnake converts camelCase keys to snake_case.
+	KeyTransformCamelToSnake KeyTransform = "camelToSnake"
+)
+
+// ExternalSecretDataFromRemoteRef defines the Provider data location of a dataFrom entry
+// and how the fetched keys are filtered.
+type ExternalSecretDataFromRemoteRef struct {
+	ExternalSecretDataRemoteRef `json:",inline"`
+
+	// SecretStoreRef overrides spec.secretStoreRef for this entry,
+	// e.g. to assemble one Secret from keys of different Providers
+	// +optional
+	SecretStoreRef *SecretStoreRef `json:"secretStoreRef,omitempty"`
+
+	// Include lists keys of the Provider data that are written to the Secret.
+	// If set, all other keys are dropped. Include is evaluated before Exclude,
+	// so a key matching both lists is excluded.
+	// Entries are key names that only include that exact key, entries prefixed
+	// with regexp: are regular expressions that have to match the whole key
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude lists keys of the Provider data that are not written to the Secret.
+	// Entries are key names that only exclude that exact key, entries prefixed
+	// with regexp: are regular expressions that have to match the whole key
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// StripPrefix is removed from the keys of the Provider data after Include and Exclude
+	// were evaluated, e.g. APP_PROD_DB_HOST becomes DB_HOST with the prefix APP_PROD_.
+	// Keys without the prefix are written unchanged unless DropUnprefixedKeys is set.
+	// Keys that end up with the same name are rejected
+	// +optional
+	StripPrefix string `json:"stripPrefix,omitempty"`
+
+	// DropUnprefixedKeys drops the keys of the Provider data that do not start with StripPrefix.
+	// It has no effect without StripPrefix
+	// +optional
+	DropUnprefixedKeys bool `json:"dropUnprefixedKeys,omitempty"`
+
+	// KeyTransform lists transformations that are applied in order to the keys of the Provider data
+	// after the prefix was stripped. Keys that end up with the same name are rejected.
+	// E.g. [camelToSnake, toUpper] turns dbPassword into DB_PASSWORD
+	// +optional
+	KeyTransform []KeyTransform `json:"keyTransform,omitempty"`
+
+	// JSONKey additionally stores all keys of this entry serialized as one JSON object
+	// under the given Secret key. It must not collide with one of the fetched keys.
+	// The keys are sorted and the JSON is compact without HTML escaping, so the serialization
+	// of the same data is byte-identical and can be hashed or signed.
+	// Values that are not valid UTF-8 are rejected
+	// +optional
+	JSONKey string `json:"jsonKey,omitempty"`
+}
+
+// CallBudgetPolicy defines what happens when an ExternalSecret exceeds its call budget.
+// +kubebuilder:validation:Enum=Lenient;Strict
+type CallBudgetPolicy string
+
+const (
+	// CallBudgetPolicyLenient writes the keys that were resolved before the budget was exceeded.
+	CallBudgetPolicyLenient CallBudgetPolicy = "Lenient"
+
+	// CallBudgetPolicyStrict fails the reconcile without writing the Secret.
+	CallBudgetPolicyStrict CallBudgetPolicy = "Strict"
+)
+
+// CallBudget limits the number of Provider calls of a single reconcile.
+type CallBudget struct {
+	// MaxCalls is the number of Provider calls a reconcile may make.
+	// Values served from the cache of pinned versions are not counted
+	// +kubebuilder:validation:Minimum=1
+	MaxCalls int `json:"maxCalls"`
+
+	// Policy defines what happens when the budget is exceeded. Strict fails the reconcile,
+	// Lenient writes the keys that were resolved until then. Defaults to Strict
+	// +optional
+	Policy CallBudgetPolicy `json:"policy,omitempty"`
 }
 
 // ExternalSecretSpec defines the desired state of ExternalSecret.
 type ExternalSecretSpec struct {
-	SecretStoreRef SecretStoreRef `json:"secretStoreRef"`
+	// SecretStoreRef is required to fetch data from a Provider. It can be omitted
+	// if the Secret is rendered from in-cluster values of spec.target.template.templateFrom only
+	// or if all data and dataFrom entries have their own secretStoreRef
+	// +optional
+	SecretStoreRef SecretStoreRef `json:"secretStoreRef,omitempty"`
 
 	Target ExternalSecretTarget `json:"target"`
 
 	// RefreshInterval is the amount of time before the values are read again from the SecretStore provider
 	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h"
-	// May be set to zero to fetch and create it once. Defaults to 1h.
-	// +kubebuilder:default="1h"
+	// May be set to zero to fetch and create it once. Defaults to spec.refreshInterval
+	// of the store or 1h if the store does not set it either.
+	// +optional
 	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
 
+	// MinWriteInterval is the minimum amount of time between two writes to the target Secret.
+	// Changes observed within that window are coalesced and only the latest value is written
+	// once the window has passed. Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h"
+	// +optional
+	MinWriteInterval *metav1.Duration `json:"minWriteInterval,omitempty"`
+
+	// RefreshFailurePolicy defines what happens to the target Secret when a refresh fails.
+	// The Secret and its copies always keep the last successfully synced data.
+	// Retain retries the refresh after the refresh interval, Strict fails the
+	// reconcile and retries it with exponential backoff.
+	// Defaults to Retain
+	// +optional
+	RefreshFailurePolicy RefreshFailurePolicy `json:"refreshFailurePolicy,omitempty"`
+
+	// SyncPolicy defines whether the target Secret is synced on every refresh (Always)
+	// or only until the first successful sync (Once), e.g. for seed secrets that are
+	// managed manually afterwards. Changing the value of AnnotationForceSync syncs it again.
+	// Defaults to Always
+	// +optional
+	SyncPolicy SyncPolicy `json:"syncPolicy,omitempty"`
+
 	// Data defines the connection between the Kubernetes Secret keys and the Provider data
 	// +optional
 	Data []ExternalSecretData `json:"data,omitempty"`
@@ -125,15 +525,34 @@ type ExternalSecretSpec struct {
 	// DataFrom is used to fetch all properties from a specific Provider data
 	// If multiple entries are specified, the Secret keys are merged in the specified order
 	// +optional
-	DataFrom []ExternalSecretDataRemoteRef `json:"dataFrom,omitempty"`
+	DataFrom []ExternalSecretDataFromRemoteRef `json:"dataFrom,omitempty"`
+
+	// DependsOn lists ExternalSecrets in the same namespace that have to be synced
+	// before this ExternalSecret is reconciled. Dependency cycles are rejected
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// CallBudget limits the number of Provider calls of a single reconcile,
+	// e.g. to protect a Provider quota that is shared with other workloads
+	// +optional
+	CallBudget *CallBudget `json:"callBudget,omitempty"`
 }
 
 type ExternalSecretConditionType string
 
 const (
 	ExternalSecretReady ExternalSecretConditionType = "Ready"
+	// ExternalSecretPaused is true while the ExternalSecret is disabled with AnnotationDisabled.
+	ExternalSecretPaused ExternalSecretConditionType = "Paused"
 )
 
+// AnnotationDisabled pauses the reconciliation of an ExternalSecret if it is set to "true".
+// Nothing is fetched from the Provider and the Secret is not written until it is removed.
+const AnnotationDisabled = "external-secrets.io/disabled"
+
+// AnnotationForceSync syncs an ExternalSecret with syncPolicy Once again if its value changes.
+const AnnotationForceSync = "external-secrets.io/force-sync"
+
 type ExternalSecretStatusCondition struct {
 	Type   ExternalSecretConditionType `json:"type"`
 	Status corev1.ConditionStatus      `json:"status"`
@@ -153,6 +572,36 @@ const (
 	ConditionReasonSecretSynced = "SecretSynced"
 	// ConditionReasonSecretSyncedError indicates that there was an error syncing the secret.
 	ConditionReasonSecretSyncedError = "SecretSyncedError"
+	// ConditionReasonTemplateRefError indicates that a referenced template could not be resolved.
+	ConditionReasonTemplateRefError = "TemplateRefError"
+	// ConditionReasonSecretTooLarge indicates that the Secret data exceeds the size limit of Kubernetes Secrets.
+	ConditionReasonSecretTooLarge = "SecretTooLarge"
+	// ConditionReasonDisallowedKMSKey indicates that a secret is not encrypted with a KMS key allowed by the store.
+	ConditionReasonDisallowedKMSKey = "DisallowedKMSKey"
+	// ConditionReasonNamespaceNotAllowed indicates that spec.target.namespaces contains a namespace the operator does not allow.
+	ConditionReasonNamespaceNotAllowed = "NamespaceNotAllowed"
+	// ConditionReasonWaitingForDependency indicates that an ExternalSecret of spec.dependsOn is not synced yet.
+	ConditionReasonWaitingForDependency = "WaitingForDependency"
+	// ConditionReasonDependencyCycle indicates that spec.dependsOn leads back to the ExternalSecret itself.
+	ConditionReasonDependencyCycle = "DependencyCycle"
+	// ConditionReasonInvalidKeyRef indicates that the key of a remote ref could not be read from its keyFrom source.
+	ConditionReasonInvalidKeyRef = "InvalidKeyRef"
+	// ConditionReasonDecryptionFailed indicates that a fetched value could not be decrypted.
+	ConditionReasonDecryptionFailed = "DecryptionFailed"
+	// ConditionReasonAccessDenied indicates that the Provider denied access to a secret.
+	ConditionReasonAccessDenied = "AccessDenied"
+	// ConditionReasonSecretNotFound indicates that a secret does not exist in the Provider.
+	ConditionReasonSecretNotFound = "SecretNotFound"
+	// ConditionReasonProviderDecryptionFailed indicates that the Provider could not decrypt a secret, e.g. with its KMS key.
+	ConditionReasonProviderDecryptionFailed = "ProviderDecryptionFailed"
+	// ConditionReasonThrottled indicates that the Provider rejected requests because of rate limits.
+	ConditionReasonThrottled = "Throttled"
+	// ConditionReasonCallBudgetExceeded indicates that the ExternalSecret made more Provider calls than allowed by spec.callBudget.
+	ConditionReasonCallBudgetExceeded = "CallBudgetExceeded"
+	// ConditionReasonDisabled indicates that the ExternalSecret is paused by AnnotationDisabled.
+	ConditionReasonDisabled = "Disabled"
+	// ConditionReasonResumed indicates that AnnotationDisabled was removed and the ExternalSecret is reconciled again.
+	ConditionReasonResumed = "Resumed"
 )
 
 type ExternalSecretStatus struct {
@@ -161,8 +610,30 @@ type ExternalSecretStatus struct {
 	// the target secret updated
 	RefreshTime metav1.Time `json:"refreshTime,omitempty"`
 
+	// +nullable
+	// lastWriteTime is the time and date the target secret was last created or updated
+	LastWriteTime metav1.Time `json:"lastWriteTime,omitempty"`
+
 	// +optional
 	Conditions []ExternalSecretStatusCondition `json:"conditions,omitempty"`
+
+	// SkippedKeys lists the Secret keys of optional data entries that could not be fetched
+	// during the last sync
+	// +optional
+	SkippedKeys []string `json:"skippedKeys,omitempty"`
+
+	// SecretValueUpdates counts the syncs that changed the data of the target Secret
+	// +optional
+	SecretValueUpdates int64 `json:"secretValueUpdates,omitempty"`
+
+	// SyncCompletedTime is the time an ExternalSecret with syncPolicy Once was synced.
+	// It is not fetched or written again until AnnotationForceSync changes
+	// +optional
+	SyncCompletedTime *metav1.Time `json:"syncCompletedTime,omitempty"`
+
+	// SyncCompletedForceSync is the value of AnnotationForceSync at SyncCompletedTime
+	// +optional
+	SyncCompletedForceSync string `json:"syncCompletedForceSync,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -34,6 +34,7 @@ type GenericStore interface {
 
 	GetObjectMeta() *metav1.ObjectMeta
 	GetSpec() *SecretStoreSpec
+	GetStatus() *SecretStoreStatus
 	GetNamespacedName() string
 }
 
@@ -49,6 +50,10 @@ func (c *SecretStore) GetSpec() *SecretStoreSpec {
 	return &c.Spec
 }
 
+func (c *SecretStore) GetStatus() *SecretStoreStatus {
+	return &c.Status
+}
+
 func (c *SecretStore) GetNamespacedName() string {
 	return fmt.Sprintf("%s/%s", c.Namespace, c.Name)
 }
@@ -69,6 +74,10 @@ func (c *ClusterSecretStore) GetSpec() *SecretStoreSpec {
 	return &c.Spec
 }
 
+func (c *ClusterSecretStore) GetStatus() *SecretStoreStatus {
+	return &c.Status
+}
+
 func (c *ClusterSecretStore) Copy() GenericStore {
 	return c.DeepCopy()
 }
@@ -23,6 +23,18 @@ type AWSAuth struct {
 	SecretRef AWSAuthSecretRef `json:"secretRef"`
 }
 
+// AWSAuthMethod is one of the authentication methods of AWSProvider.AuthMethods.
+type AWSAuthMethod struct {
+	// Name identifies the method, it is recorded in the status of the store
+	// once the method is used
+	Name string `json:"name"`
+
+	// SecretRef reads static credentials from secrets.
+	// If not set aws sdk will infer credentials from your environment
+	// +optional
+	SecretRef *AWSAuthSecretRef `json:"secretRef,omitempty"`
+}
+
 // AWSAuthSecretRef holds secret references for aws credentials
 // both AccessKeyID and SecretAccessKey must be defined in order to properly authenticate.
 type AWSAuthSecretRef struct {
@@ -58,10 +70,58 @@ type AWSProvider struct {
 	// +optional
 	Auth *AWSAuth `json:"auth"`
 
+	// AuthMethods is an ordered list of authentication methods. They are tried
+	// in order until credentials can be retrieved with one of them, e.g. until
+	// the role can be assumed. It can not be combined with Auth
+	// +optional
+	AuthMethods []AWSAuthMethod `json:"authMethods,omitempty"`
+
 	// Role is a Role ARN which the SecretManager provider will assume
 	// +optional
 	Role string `json:"role,omitempty"`
 
 	// AWS Region to be used for the provider
-	Region string `json:"region"`
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// RegionFrom reads the region from a ConfigMap whenever the store is used.
+	// It can not be combined with Region
+	// +optional
+	RegionFrom *AWSRegionSource `json:"regionFrom,omitempty"`
+
+	// AllowedKMSKeyIDs lists the KMS keys a secret must be encrypted with to be synced.
+	// Entries can be key ids, key ARNs, alias names (alias/...) or alias ARNs and must
+	// match the key the secret was configured with. Secrets without a configured key
+	// use alias/aws/secretsmanager. The check is skipped if the list is empty.
+	// Only supported by SecretsManager
+	// +optional
+	AllowedKMSKeyIDs []string `json:"allowedKMSKeyIDs,omitempty"`
+
+	// ReplicaFallback reads a secret from its primary region and its replica regions
+	// if it is not found in the region of the store. The regions are taken from the
+	// replication status AWS reports for the secret in PrimaryRegion, only replicas
+	// that are in sync are read. It requires PrimaryRegion.
+	// Only supported by SecretsManager
+	// +optional
+	ReplicaFallback bool `json:"replicaFallback,omitempty"`
+
+	// PrimaryRegion is the region the secrets are replicated from. The replication
+	// status is read from it, as AWS does not describe a secret in a region it is missing in
+	// +optional
+	PrimaryRegion string `json:"primaryRegion,omitempty"`
+
+	// ContentTypeFromTags reads the content type of a secret from its
+	// external-secrets.io/content-type tag when a property or dataFrom is requested.
+	// It requires the secretsmanager:DescribeSecret permission.
+	// Only supported by SecretsManager
+	// +optional
+	ContentTypeFromTags bool `json:"contentTypeFromTags,omitempty"`
+}
+
+// AWSRegionSource defines where the region of the provider is read from.
+type AWSRegionSource struct {
+	// ConfigMapKeyRef selects the ConfigMap key holding the region.
+	// The namespace is required for a ClusterSecretStore, a SecretStore
+	// reads the ConfigMap from the namespace of the ExternalSecret
+	ConfigMapKeyRef esmeta.ConfigMapKeySelector `json:"configMapKeyRef"`
 }
@@ -28,6 +28,11 @@ type SecretStoreSpec struct {
 
 	// Used to configure the provider. Only one provider may be set
 	Provider *SecretStoreProvider `json:"provider"`
+
+	// RefreshInterval is the default refresh interval of the ExternalSecrets using this store.
+	// It is used if an ExternalSecret does not set spec.refreshInterval itself. Defaults to 1h
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
 }
 
 // SecretStoreProvider contains the provider-specific configration.
@@ -49,6 +54,13 @@ const (
 	SecretStoreReady SecretStoreConditionType = "Ready"
 )
 
+const (
+	// ConditionReasonStoreValidated indicates that the store configuration was validated with the provider.
+	ConditionReasonStoreValidated = "StoreValidated"
+	// ConditionReasonStoreValidationFailed indicates that the store configuration could not be validated.
+	ConditionReasonStoreValidationFailed = "ValidationFailed"
+)
+
 type SecretStoreStatusCondition struct {
 	Type   SecretStoreConditionType `json:"type"`
 	Status corev1.ConditionStatus   `json:"status"`
@@ -67,12 +79,23 @@ type SecretStoreStatusCondition struct {
 type SecretStoreStatus struct {
 	// +optional
 	Conditions []SecretStoreStatusCondition `json:"conditions"`
+
+	// LastCheckTime is the time the store was last validated with the provider
+	// +optional
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// AuthMethod is the name of the authentication method the store was
+	// last validated with, if the provider supports multiple methods
+	// +optional
+	AuthMethod string `json:"authMethod,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 
 // SecretStore represents a secure external location for storing secrets, which can be referenced as part of `storeRef` fields.
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Last Check",type="date",JSONPath=".status.lastCheckTime"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,categories={externalsecrets},shortName=ss
 type SecretStore struct {
@@ -96,13 +119,16 @@ type SecretStoreList struct {
 
 // ClusterSecretStore represents a secure external location for storing secrets, which can be referenced as part of `storeRef` fields.
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Last Check",type="date",JSONPath=".status.lastCheckTime"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={externalsecrets},shortName=css
 type ClusterSecretStore struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec SecretStoreSpec `json:"spec,omitempty"`
+	Spec   SecretStoreSpec   `json:"spec,omitempty"`
+	Status SecretStoreStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
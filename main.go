@@ -16,7 +16,10 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -27,6 +30,7 @@ import (
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
 	"github.com/external-secrets/external-secrets/pkg/controllers/externalsecret"
 	"github.com/external-secrets/external-secrets/pkg/controllers/secretstore"
+	"github.com/external-secrets/external-secrets/pkg/validate"
 )
 
 var (
@@ -40,18 +44,38 @@ func init() {
 }
 
 func main() {
+	// validate an ExternalSecret offline against a fake store
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		if err := validate.Run(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var controllerClass string
 	var enableLeaderElection bool
+	var requeueJitter float64
+	var allowedTargetNamespaces string
+	var storeValidationInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&controllerClass, "controller-class", "default", "the controller is instantiated with a specific controller name and filters ES based on this property")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.Float64Var(&requeueJitter, "requeue-jitter", 0.1, "the fraction by which the refresh interval of an ExternalSecret is randomly shortened or extended to spread out refreshes. Must be at least 0 and below 1, set to 0 to disable")
+	flag.StringVar(&allowedTargetNamespaces, "allowed-target-namespaces", "", "comma separated list of namespaces ExternalSecrets may copy their Secret to with spec.target.namespaces. Copies are refused if empty")
+	flag.DurationVar(&storeValidationInterval, "store-validation-interval", 5*time.Minute, "the time between two validations of a SecretStore with its provider")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	if requeueJitter < 0 || requeueJitter >= 1 {
+		setupLog.Error(fmt.Errorf("must be in [0, 1), got %v", requeueJitter), "invalid --requeue-jitter")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -65,19 +89,33 @@ func main() {
 	}
 
 	if err = (&secretstore.Reconciler{
-		Client:          mgr.GetClient(),
-		Log:             ctrl.Log.WithName("controllers").WithName("SecretStore"),
-		Scheme:          mgr.GetScheme(),
-		ControllerClass: controllerClass,
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("SecretStore"),
+		Scheme:             mgr.GetScheme(),
+		ControllerClass:    controllerClass,
+		ValidationInterval: storeValidationInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SecretStore")
 		os.Exit(1)
 	}
+	if err = (&secretstore.ClusterStoreReconciler{
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("ClusterSecretStore"),
+		Scheme:             mgr.GetScheme(),
+		ControllerClass:    controllerClass,
+		ValidationInterval: storeValidationInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterSecretStore")
+		os.Exit(1)
+	}
 	if err = (&externalsecret.Reconciler{
-		Client:          mgr.GetClient(),
-		Log:             ctrl.Log.WithName("controllers").WithName("ExternalSecret"),
-		Scheme:          mgr.GetScheme(),
-		ControllerClass: controllerClass,
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("ExternalSecret"),
+		Scheme:                  mgr.GetScheme(),
+		ControllerClass:         controllerClass,
+		RequeueJitter:           requeueJitter,
+		Recorder:                mgr.GetEventRecorderFor("external-secrets"),
+		AllowedTargetNamespaces: splitList(allowedTargetNamespaces),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ExternalSecret")
 		os.Exit(1)
@@ -89,3 +127,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits a comma separated flag value and drops empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
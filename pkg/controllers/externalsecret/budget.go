@@ -0,0 +1,107 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package externalsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+// callBudgetExceededError is returned once a reconcile makes more
+// Provider calls than allowed by spec.callBudget.
+type callBudgetExceededError struct {
+	maxCalls int
+}
+
+func (e *callBudgetExceededError) Error() string {
+	return fmt.Sprintf("exceeded the budget of %d provider calls per reconcile, pin versions to serve them from the cache or raise spec.callBudget.maxCalls", e.maxCalls)
+}
+
+// callBudget counts the Provider calls of a reconcile. It is shared by the
+// clients of all stores the ExternalSecret uses.
+type callBudget struct {
+	maxCalls int
+	calls    int
+}
+
+// newCallBudget returns the call budget of the ExternalSecret or nil if it has none.
+// A new budget has to be used for every reconcile.
+func newCallBudget(externalSecret *esv1alpha1.ExternalSecret) *callBudget {
+	if externalSecret.Spec.CallBudget == nil {
+		return nil
+	}
+	return &callBudget{maxCalls: externalSecret.Spec.CallBudget.MaxCalls}
+}
+
+func (b *callBudget) spend() error {
+	if b.calls >= b.maxCalls {
+		return &callBudgetExceededError{maxCalls: b.maxCalls}
+	}
+	b.calls++
+	return nil
+}
+
+// budgetClient fails all Provider calls after the budget of a reconcile is spent.
+type budgetClient struct {
+	provider.SecretsClient
+	*callBudget
+}
+
+// withCallBudget wraps the client of a store with the call budget of the ExternalSecret.
+func withCallBudget(budget *callBudget, secretClient provider.SecretsClient) provider.SecretsClient {
+	if budget == nil {
+		return secretClient
+	}
+	return &budgetClient{
+		SecretsClient: secretClient,
+		callBudget:    budget,
+	}
+}
+
+func (c *budgetClient) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+	if err := c.spend(); err != nil {
+		return nil, err
+	}
+	return c.SecretsClient.GetSecret(ctx, ref)
+}
+
+func (c *budgetClient) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	if err := c.spend(); err != nil {
+		return nil, err
+	}
+	return c.SecretsClient.GetSecretMap(ctx, ref)
+}
+
+func (c *budgetClient) Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	if err := c.spend(); err != nil {
+		return false, err
+	}
+	return c.SecretsClient.Exists(ctx, ref)
+}
+
+func isCallBudgetExceeded(err error) bool {
+	var exceeded *callBudgetExceededError
+	return errors.As(err, &exceeded)
+}
+
+// isPartialSync returns true if the call budget was exceeded and the
+// keys that were resolved until then should be written anyway.
+func isPartialSync(externalSecret *esv1alpha1.ExternalSecret, err error) bool {
+	budget := externalSecret.Spec.CallBudget
+	return budget != nil && budget.Policy == esv1alpha1.CallBudgetPolicyLenient && isCallBudgetExceeded(err)
+}
@@ -0,0 +1,166 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package externalsecret
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+// maxCachedVersions bounds the memory used by the versionCache.
+// The cache is cleared once the limit is reached.
+const maxCachedVersions = 1000
+
+// versionCache holds the values of pinned secret versions.
+// The zero value is ready to use.
+type versionCache struct {
+	mu     sync.Mutex
+	values map[versionCacheKey][]byte
+	maps   map[versionCacheKey]map[string][]byte
+}
+
+// versionCacheKey identifies a value by the store it was fetched from.
+// The store generation is part of the key, so values are fetched again
+// once the store is changed.
+type versionCacheKey struct {
+	store           types.UID
+	storeGeneration int64
+	ref             esv1alpha1.ExternalSecretDataRemoteRef
+}
+
+func (c *versionCache) getValue(key versionCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	val, ok := c.values[key]
+	return val, ok
+}
+
+func (c *versionCache) setValue(key versionCacheKey, val []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil || len(c.values)+len(c.maps) >= maxCachedVersions {
+		c.reset()
+	}
+	c.values[key] = val
+}
+
+func (c *versionCache) getMap(key versionCacheKey) (map[string][]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.maps[key]
+	if !ok {
+		return nil, false
+	}
+	// callers may modify the map
+	out := make(map[string][]byte, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out, true
+}
+
+func (c *versionCache) setMap(key versionCacheKey, m map[string][]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maps == nil || len(c.values)+len(c.maps) >= maxCachedVersions {
+		c.reset()
+	}
+	stored := make(map[string][]byte, len(m))
+	for k, v := range m {
+		stored[k] = v
+	}
+	c.maps[key] = stored
+}
+
+// reset must be called with the lock held.
+func (c *versionCache) reset() {
+	c.values = make(map[versionCacheKey][]byte)
+	c.maps = make(map[versionCacheKey]map[string][]byte)
+}
+
+// cachingClient serves pinned versions from the versionCache
+// and fetches all other values from the provider.
+type cachingClient struct {
+	provider.SecretsClient
+	pinner          provider.VersionPinner
+	cache           *versionCache
+	store           types.UID
+	storeGeneration int64
+}
+
+// withVersionCache wraps the client of the store with the versionCache of the reconciler.
+// Values that are not cached are fetched with fetcher, which wraps the client of the store.
+// Clients of providers that can not tell whether a version is pinned are not wrapped.
+func (r *Reconciler) withVersionCache(store esv1alpha1.GenericStore, secretClient, fetcher provider.SecretsClient) provider.SecretsClient {
+	pinner, ok := secretClient.(provider.VersionPinner)
+	if !ok {
+		return fetcher
+	}
+	return &cachingClient{
+		SecretsClient:   fetcher,
+		pinner:          pinner,
+		cache:           &r.versionCache,
+		store:           store.GetUID(),
+		storeGeneration: store.GetGeneration(),
+	}
+}
+
+// key drops the pointer fields of ref: they are compared by address, so they would differ
+// on every reconcile. The cache holds values before decryption and keys are resolved already.
+func (c *cachingClient) key(ref esv1alpha1.ExternalSecretDataRemoteRef) versionCacheKey {
+	ref.KeyFrom = nil
+	ref.Decrypt = nil
+	return versionCacheKey{
+		store:           c.store,
+		storeGeneration: c.storeGeneration,
+		ref:             ref,
+	}
+}
+
+// GetSecret returns a single secret, pinned versions are only fetched once.
+func (c *cachingClient) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+	if !c.pinner.IsPinnedVersion(ref) {
+		return c.SecretsClient.GetSecret(ctx, ref)
+	}
+	if val, ok := c.cache.getValue(c.key(ref)); ok {
+		return val, nil
+	}
+	val, err := c.SecretsClient.GetSecret(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.setValue(c.key(ref), val)
+	return val, nil
+}
+
+// GetSecretMap returns multiple k/v pairs, pinned versions are only fetched once.
+func (c *cachingClient) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	if !c.pinner.IsPinnedVersion(ref) {
+		return c.SecretsClient.GetSecretMap(ctx, ref)
+	}
+	if m, ok := c.cache.getMap(c.key(ref)); ok {
+		return m, nil
+	}
+	m, err := c.SecretsClient.GetSecretMap(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.setMap(c.key(ref), m)
+	return m, nil
+}
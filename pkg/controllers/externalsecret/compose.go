@@ -0,0 +1,85 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package externalsecret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+// composeSecret fetches every field of the composition and returns
+// the JSON document built from them.
+func composeSecret(ctx context.Context, providerClient provider.SecretsClient, composition esv1alpha1.ExternalSecretComposition) ([]byte, error) {
+	doc := make(map[string]interface{})
+	for _, field := range composition.Fields {
+		value, err := providerClient.GetSecret(ctx, field.RemoteRef)
+		if err != nil {
+			return nil, fmt.Errorf("path %q (key %q): %w", field.Path, field.RemoteRef.Key, err)
+		}
+		if err := setComposedValue(doc, field.Path, composedValue(value)); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// composedValue embeds JSON objects and arrays as they are,
+// everything else is stored as a JSON string.
+func composedValue(value []byte) interface{} {
+	trimmed := strings.TrimSpace(string(value))
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid(value) {
+		return json.RawMessage(value)
+	}
+	return string(value)
+}
+
+// setComposedValue stores the value at the dot separated path and creates the
+// parent objects on the way. A path that was already set, or that is the parent
+// or the child of a path that was already set, is rejected.
+func setComposedValue(doc map[string]interface{}, path string, value interface{}) error {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("invalid path %q: empty segment", path)
+		}
+	}
+
+	current := doc
+	for i, segment := range segments[:len(segments)-1] {
+		existing, ok := current[segment]
+		if !ok {
+			next := make(map[string]interface{})
+			current[segment] = next
+			current = next
+			continue
+		}
+		next, ok := existing.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("path %q collides with path %q", path, strings.Join(segments[:i+1], "."))
+		}
+		current = next
+	}
+
+	last := segments[len(segments)-1]
+	if _, ok := current[last]; ok {
+		return fmt.Errorf("path %q collides with another path", path)
+	}
+	current[last] = value
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package externalsecret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+// decryptionError is returned if a fetched value can not be decrypted.
+// It never contains the identities or decrypted data.
+type decryptionError struct {
+	err error
+}
+
+func (e *decryptionError) Error() string {
+	return fmt.Sprintf("could not decrypt value: %s", e.err)
+}
+
+func (e *decryptionError) Unwrap() error {
+	return e.err
+}
+
+func isDecryptionError(err error) bool {
+	var decryption *decryptionError
+	return errors.As(err, &decryption)
+}
+
+// decryptingClient decrypts the values of remote refs with decrypt
+// after they were fetched from the provider.
+type decryptingClient struct {
+	provider.SecretsClient
+	kube      client.Client
+	namespace string
+}
+
+// withDecryption wraps the client of the store, so identities of Secrets
+// are read in the namespace of the ExternalSecret.
+func withDecryption(kube client.Client, namespace string, secretClient provider.SecretsClient) provider.SecretsClient {
+	return &decryptingClient{
+		SecretsClient: secretClient,
+		kube:          kube,
+		namespace:     namespace,
+	}
+}
+
+func (c *decryptingClient) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+	data, err := c.SecretsClient.GetSecret(ctx, ref)
+	if err != nil || ref.Decrypt == nil {
+		return data, err
+	}
+	if ref.Decrypt.Age == nil {
+		return nil, &decryptionError{err: errors.New("decrypt.age must be set")}
+	}
+	identities, err := c.ageIdentities(ctx, ref.Decrypt.Age)
+	if err != nil {
+		return nil, &decryptionError{err: err}
+	}
+	plaintext, err := ageDecrypt(data, identities)
+	if err != nil {
+		return nil, &decryptionError{err: err}
+	}
+	return plaintext, nil
+}
+
+// ageDecrypt decrypts a binary or ASCII armored age file.
+func ageDecrypt(data []byte, identities []age.Identity) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+	// the payload is authenticated in chunks, so nothing is returned if a later chunk fails
+	plaintext, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+func (c *decryptingClient) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	if ref.Decrypt != nil {
+		return nil, &decryptionError{err: errors.New("decrypt is not supported with dataFrom")}
+	}
+	return c.SecretsClient.GetSecretMap(ctx, ref)
+}
+
+func (c *decryptingClient) ageIdentities(ctx context.Context, src *esv1alpha1.AgeDecryption) ([]age.Identity, error) {
+	var data []byte
+	switch {
+	case src.IdentitySecretRef != nil && src.IdentityRemoteRef != nil:
+		return nil, fmt.Errorf("only one of identitySecretRef and identityRemoteRef can be set")
+	case src.IdentitySecretRef != nil:
+		var secret corev1.Secret
+		name := src.IdentitySecretRef.Name
+		if err := c.kube.Get(ctx, client.ObjectKey{Name: name, Namespace: c.namespace}, &secret); err != nil {
+			return nil, fmt.Errorf("could not get Secret %q: %w", name, err)
+		}
+		data = secret.Data[src.IdentitySecretRef.Key]
+	case src.IdentityRemoteRef != nil:
+		var err error
+		data, err = c.SecretsClient.GetSecret(ctx, esv1alpha1.ExternalSecretDataRemoteRef{
+			Key:      src.IdentityRemoteRef.Key,
+			Version:  src.IdentityRemoteRef.Version,
+			Property: src.IdentityRemoteRef.Property,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get identities from provider key %q: %w", src.IdentityRemoteRef.Key, err)
+		}
+	default:
+		return nil, fmt.Errorf("one of identitySecretRef and identityRemoteRef must be set")
+	}
+	return age.ParseIdentities(bytes.NewReader(data))
+}
+
+// referencesIdentitySecret returns true if a remote ref of the ExternalSecret
+// reads its age identities from the Secret with the given name.
+func referencesIdentitySecret(es *esv1alpha1.ExternalSecret, name string) bool {
+	for _, ref := range remoteRefs(es) {
+		if ref.Decrypt == nil || ref.Decrypt.Age == nil || ref.Decrypt.Age.IdentitySecretRef == nil {
+			continue
+		}
+		if ref.Decrypt.Age.IdentitySecretRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalsecret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	// ageIdentityFile is a key file as written by age-keygen,
+	// its public key is age1z39m2y7y98h6zue3lydqdst5lpt6vu08t9pputfwj43eg76fpffssu0nlv
+	ageIdentityFile = `# created: 2021-04-01T12:00:00Z
+# public key: age1z39m2y7y98h6zue3lydqdst5lpt6vu08t9pputfwj43eg76fpffssu0nlv
+AGE-SECRET-KEY-1403GTPWGJJ0DS4QGWZGNUHRD2TMYHT96CL4JVQTEZDSTF9A66RNS7SJNH6
+`
+	ageOtherIdentity = "AGE-SECRET-KEY-12JRPAJAXC6W64APQ8KCKMJVNJV5UKJDL5TF94U4DFR4VS6GF4TFSUR32MZ"
+
+	ageFixturePlaintext = "postgres://app:s3cr3t@db:5432/app"
+	// ageArmoredFixture is ageFixturePlaintext encrypted for ageIdentityFile
+	ageArmoredFixture = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSAxdjBmT3kxeC9XdFdSZy9O
+aDNiTERJOTU4VlNQZ3F6cUNRazVtSmRBakNFCmtVM29Lck9MRHk2MWhDd2UrMkxT
+V3IyazZ6bmNScDVMbVFycFNqd0lsVm8KLS0tIHZhUERVQUtHd2V1WlhGZlpYWnNP
+bUdQdHdHcG1OQmlFTSt6RGxBRTNIM0kKN/hsuQeHOMNw8H6NO1g7raWqycqeFOLM
+FBVcNCfB2PHu1NesQAUbFaURsm/8QEEu8cJwqleGBvXulsc5RFuY9Rg=
+-----END AGE ENCRYPTED FILE-----
+`
+	// ageBinaryFixture is "binary-value" encrypted for ageOtherIdentity and ageIdentityFile, base64 encoded
+	ageBinaryFixture = "YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBBMWF4YkpXN0lyTG1HQ1hPRkhVSk9DSWxib3ZCaTRlc3FnaStqdEJjWENBCjZHZDNsNjZhOUJwL3U1WTJVK3d2YlJQTHR0dktsUSsvWjVidndGYVBCZ3MKLT4gWDI1NTE5IFp4Y0tteEJLS2t2dk40aEJjZDVTNzdoY2txN1hFYkZlZlBmMTRBeVYxUU0KWXZZUFJrOTV6WklTajhSNzc0eUZFL0RoYXd0NUFhUTllbzVXUW9UcTJwSQotLS0gU3hEY3Z5TEFSN3NnUEdOTG1PSndybXYvMnl2Y0ZvRkVJU3lDSzM5aElkWQrDXcqXWlz72/ZyKfTBZre90QWHdtc0xye3qkAtNg+FpsrFE9Y6a50GXf39Fg=="
+)
+
+func TestAgeDecrypt(t *testing.T) {
+	binary, err := base64.StdEncoding.DecodeString(ageBinaryFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := ioutil.ReadAll(armor.NewReader(strings.NewReader(ageArmoredFixture)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamperedPayload := append([]byte{}, fixture...)
+	tamperedPayload[len(tamperedPayload)-20] ^= 1
+
+	tbl := []struct {
+		test       string
+		data       []byte
+		identities string
+		expected   string
+		expErr     bool
+	}{
+		{test: "armored", data: []byte(ageArmoredFixture), identities: ageIdentityFile, expected: ageFixturePlaintext},
+		{test: "armored with surrounding whitespace", data: []byte("\n" + ageArmoredFixture + "\n"), identities: ageIdentityFile, expected: ageFixturePlaintext},
+		{test: "binary", data: fixture, identities: ageIdentityFile, expected: ageFixturePlaintext},
+		{test: "second recipient", data: binary, identities: ageIdentityFile, expected: "binary-value"},
+		{test: "first recipient", data: binary, identities: ageOtherIdentity, expected: "binary-value"},
+		{test: "one of several identities", data: fixture, identities: ageOtherIdentity + "\n" + ageIdentityFile, expected: ageFixturePlaintext},
+		{test: "wrong identity", data: fixture, identities: ageOtherIdentity, expErr: true},
+		{test: "truncated payload", data: fixture[:len(fixture)-1], identities: ageIdentityFile, expErr: true},
+		{test: "tampered payload", data: tamperedPayload, identities: ageIdentityFile, expErr: true},
+		{test: "not encrypted", data: []byte(ageFixturePlaintext), identities: ageIdentityFile, expErr: true},
+		{test: "armor without footer", data: []byte(strings.Split(ageArmoredFixture, "-----END")[0]), identities: ageIdentityFile, expErr: true},
+	}
+
+	for _, row := range tbl {
+		t.Run(row.test, func(t *testing.T) {
+			identities, err := age.ParseIdentities(strings.NewReader(row.identities))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ageDecrypt(row.data, identities)
+			if row.expErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				if strings.Contains(err.Error(), "s3cr3t") || got != nil {
+					t.Fatalf("plaintext was returned or leaked into the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, []byte(row.expected)) {
+				t.Errorf("unexpected plaintext: got %q, expected %q", got, row.expected)
+			}
+		})
+	}
+}
@@ -15,21 +15,43 @@ limitations under the License.
 package externalsecret
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
 	"github.com/external-secrets/external-secrets/pkg/provider"
 
 	// Loading registered providers.
@@ -41,6 +63,19 @@ import (
 
 const (
 	requeueAfter = time.Second * 30
+
+	// defaultRefreshInterval is used if neither the ExternalSecret nor its store set a refresh interval.
+	defaultRefreshInterval = time.Hour
+
+	// digestKeySuffix is appended to a Secret key to store the digest of its value.
+	digestKeySuffix = ".sha256"
+
+	// eventReasonDriftCorrected is recorded when the target Secret was recreated
+	// because it was changed outside of the controller.
+	eventReasonDriftCorrected = "DriftCorrected"
+
+	// eventReasonSecretValueUpdated is recorded when a sync changed the data of the target Secret.
+	eventReasonSecretValueUpdated = "SecretValueUpdated"
 )
 
 // Reconciler reconciles a ExternalSecret object.
@@ -49,6 +84,22 @@ type Reconciler struct {
 	Log             logr.Logger
 	Scheme          *runtime.Scheme
 	ControllerClass string
+	// RequeueJitter is the fraction by which the refresh interval is randomly
+	// shortened or extended, so refreshes of ExternalSecrets that were created
+	// together are spread out. Zero disables the jitter.
+	RequeueJitter float64
+	// Recorder records events for ExternalSecrets. Events are not recorded if it is nil.
+	Recorder record.EventRecorder
+	// AllowedTargetNamespaces lists the namespaces ExternalSecrets may copy their
+	// Secret to with spec.target.namespaces. Copies are refused if it is empty.
+	AllowedTargetNamespaces []string
+	// GetProvider returns the provider of a store. The providers registered
+	// in the schema are used if it is nil.
+	GetProvider func(esv1alpha1.GenericStore) (provider.Provider, error)
+
+	// versionCache holds the values of pinned secret versions,
+	// they are not fetched again on subsequent reconciles.
+	versionCache versionCache
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -65,92 +116,499 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	store, err := r.getStore(ctx, &externalSecret)
+	if skip, err := r.skipSync(ctx, log, &externalSecret); skip {
+		return ctrl.Result{}, err
+	}
+	resume(&externalSecret)
+
+	// dependencies are checked first, they may provide the credentials of the store
+	reason, err := r.checkDependencies(ctx, &externalSecret)
 	if err != nil {
-		log.Error(err, "could not get store reference")
-		conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionFalse, esv1alpha1.ConditionReasonSecretSyncedError, err.Error())
+		log.Info("waiting for dependencies", "reason", reason, "message", err.Error())
+		conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionFalse, reason, err.Error())
 		SetExternalSecretCondition(&externalSecret, *conditionSynced)
+		err = r.Status().Update(ctx, &externalSecret)
+		if err != nil {
+			log.Error(err, "unable to update status")
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	secretClient, result := r.getSecretsClient(ctx, log, &externalSecret, syncCallsMetricLabels)
+	if result != nil {
+		return *result, nil
+	}
 
+	templateFrom, err := r.getTemplateFrom(ctx, &externalSecret)
+	if err != nil {
+		log.Error(err, "could not get referenced templates")
+		conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionFalse, esv1alpha1.ConditionReasonTemplateRefError, err.Error())
+		SetExternalSecretCondition(&externalSecret, *conditionSynced)
 		err = r.Status().Update(ctx, &externalSecret)
 		syncCallsError.With(syncCallsMetricLabels).Inc()
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
+	op, writeDeferredFor, err := r.syncSecret(ctx, secretClient, &externalSecret, templateFrom)
+	if err != nil && !isPartialSync(&externalSecret, err) {
+		log.Error(err, "could not reconcile ExternalSecret")
+		conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionFalse, syncErrorReason(err), err.Error())
+		SetExternalSecretCondition(&externalSecret, *conditionSynced)
+		if updateErr := r.Status().Update(ctx, &externalSecret); updateErr != nil {
+			log.Error(updateErr, "unable to update status")
+		}
+		syncCallsError.With(syncCallsMetricLabels).Inc()
+		// the secret is never touched by a failed refresh, Strict only retries it with backoff
+		if externalSecret.Spec.RefreshFailurePolicy == esv1alpha1.RefreshFailurePolicyStrict {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if op != controllerutil.OperationResultNone {
+		externalSecret.Status.LastWriteTime = metav1.NewTime(time.Now())
+	}
+	if writeDeferredFor > 0 {
+		log.V(1).Info("deferring secret update", "minWriteInterval", externalSecret.Spec.MinWriteInterval.Duration)
+	}
+	completeSync(&externalSecret, err, writeDeferredFor)
+	dur := r.requeueInterval(ctx, &externalSecret, writeDeferredFor)
+
+	SetExternalSecretCondition(&externalSecret, *syncedCondition(err))
+	externalSecret.Status.RefreshTime = metav1.NewTime(time.Now())
+	err = r.Status().Update(ctx, &externalSecret)
+	if err != nil {
+		log.Error(err, "unable to update status")
+	}
+
+	syncCallsTotal.With(syncCallsMetricLabels).Inc()
+
+	return ctrl.Result{
+		RequeueAfter: dur,
+	}, nil
+}
+
+// skipSync returns true for ExternalSecrets that are not synced. The replicas of deleted
+// ExternalSecrets are finalized and paused ExternalSecrets get the Paused condition.
+func (r *Reconciler) skipSync(ctx context.Context, log logr.Logger, externalSecret *esv1alpha1.ExternalSecret) (bool, error) {
+	switch {
+	case !externalSecret.DeletionTimestamp.IsZero():
+		return true, r.finalizeReplicas(ctx, externalSecret)
+	// removing the annotation updates the ExternalSecret, so there is no need to requeue
+	case isPaused(externalSecret):
+		return true, r.pause(ctx, externalSecret)
+	case isSyncCompleted(externalSecret):
+		log.V(1).Info("skipping ExternalSecret, it was synced once")
+		return true, nil
+	}
+	return false, nil
+}
+
+// isSyncCompleted returns true if an ExternalSecret with syncPolicy Once was synced
+// and AnnotationForceSync did not change since.
+func isSyncCompleted(externalSecret *esv1alpha1.ExternalSecret) bool {
+	return externalSecret.Spec.SyncPolicy == esv1alpha1.SyncPolicyOnce &&
+		externalSecret.Status.SyncCompletedTime != nil &&
+		externalSecret.Status.SyncCompletedForceSync == externalSecret.Annotations[esv1alpha1.AnnotationForceSync]
+}
+
+// completeSync records the successful sync of an ExternalSecret with syncPolicy Once.
+// Partial syncs and deferred writes are retried on the next refresh.
+func completeSync(externalSecret *esv1alpha1.ExternalSecret, partialErr error, writeDeferredFor time.Duration) {
+	if externalSecret.Spec.SyncPolicy != esv1alpha1.SyncPolicyOnce || partialErr != nil || writeDeferredFor > 0 {
+		return
+	}
+	now := metav1.Now()
+	externalSecret.Status.SyncCompletedTime = &now
+	externalSecret.Status.SyncCompletedForceSync = externalSecret.Annotations[esv1alpha1.AnnotationForceSync]
+}
+
+// isPaused returns true if the ExternalSecret is disabled with an annotation.
+func isPaused(externalSecret *esv1alpha1.ExternalSecret) bool {
+	return externalSecret.Annotations[esv1alpha1.AnnotationDisabled] == "true"
+}
+
+// pause sets the Paused condition. Nothing is fetched or written,
+// so the Secret keeps its current data.
+func (r *Reconciler) pause(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) error {
+	cond := GetExternalSecretCondition(externalSecret.Status, esv1alpha1.ExternalSecretPaused)
+	if cond != nil && cond.Status == corev1.ConditionTrue {
+		return nil
+	}
+	message := fmt.Sprintf("reconciliation is paused by the %s annotation", esv1alpha1.AnnotationDisabled)
+	conditionPaused := NewExternalSecretCondition(esv1alpha1.ExternalSecretPaused, corev1.ConditionTrue, esv1alpha1.ConditionReasonDisabled, message)
+	SetExternalSecretCondition(externalSecret, *conditionPaused)
+	return r.Status().Update(ctx, externalSecret)
+}
+
+// resume marks a previously paused ExternalSecret as resumed.
+// The status is written with the result of the reconcile.
+func resume(externalSecret *esv1alpha1.ExternalSecret) {
+	cond := GetExternalSecretCondition(externalSecret.Status, esv1alpha1.ExternalSecretPaused)
+	if cond == nil || cond.Status == corev1.ConditionFalse {
+		return
+	}
+	conditionPaused := NewExternalSecretCondition(esv1alpha1.ExternalSecretPaused, corev1.ConditionFalse, esv1alpha1.ConditionReasonResumed, "reconciliation was resumed")
+	SetExternalSecretCondition(externalSecret, *conditionPaused)
+}
+
+// getSecretsClient returns the client of the store of the ExternalSecret, which also serves
+// the stores of its data and dataFrom entries. If the ExternalSecret can not be synced
+// with the stores, the result of the reconcile is returned instead.
+func (r *Reconciler) getSecretsClient(ctx context.Context, log logr.Logger, externalSecret *esv1alpha1.ExternalSecret, syncCallsMetricLabels prometheus.Labels) (provider.SecretsClient, *ctrl.Result) {
+	budget := newCallBudget(externalSecret)
+	// without a store only in-cluster values are rendered
+	var secretClient provider.SecretsClient = noStoreClient{}
+	if externalSecret.Spec.SecretStoreRef.Name != "" {
+		var result *ctrl.Result
+		secretClient, result = r.getStoreClient(ctx, log, externalSecret, externalSecret.Spec.SecretStoreRef, budget, syncCallsMetricLabels)
+		if result != nil {
+			return nil, result
+		}
+	}
+	refs := entryStoreRefs(externalSecret)
+	if len(refs) == 0 {
+		return secretClient, nil
+	}
+	clients := &storeClients{
+		SecretsClient: secretClient,
+		stores:        make(map[esv1alpha1.SecretStoreRef]provider.SecretsClient, len(refs)),
+	}
+	for _, ref := range refs {
+		entryClient, result := r.getStoreClient(ctx, log, externalSecret, ref, budget, syncCallsMetricLabels)
+		if result != nil {
+			return nil, result
+		}
+		clients.stores[ref] = entryClient
+	}
+	return clients, nil
+}
+
+// getStoreClient returns the client of the store ref points to. If the ExternalSecret
+// can not be synced with the store, the result of the reconcile is returned instead.
+func (r *Reconciler) getStoreClient(ctx context.Context, log logr.Logger, externalSecret *esv1alpha1.ExternalSecret, ref esv1alpha1.SecretStoreRef, budget *callBudget, syncCallsMetricLabels prometheus.Labels) (provider.SecretsClient, *ctrl.Result) {
+	store, err := r.getStoreByRef(ctx, externalSecret.Namespace, ref)
+	if err != nil {
+		log.Error(err, "could not get store reference")
+		conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionFalse, esv1alpha1.ConditionReasonSecretSyncedError, err.Error())
+		SetExternalSecretCondition(externalSecret, *conditionSynced)
+		if err := r.Status().Update(ctx, externalSecret); err != nil {
+			log.Error(err, "unable to update status")
+		}
+		syncCallsError.With(syncCallsMetricLabels).Inc()
+		return nil, &ctrl.Result{RequeueAfter: requeueAfter}
+	}
+
 	log = log.WithValues("SecretStore", store.GetNamespacedName())
 
 	// check if store should be handled by this controller instance
 	if !shouldProcessStore(store, r.ControllerClass) {
 		log.Info("skippig unmanaged store")
-		return ctrl.Result{}, nil
+		return nil, &ctrl.Result{}
 	}
 
-	storeProvider, err := schema.GetProvider(store)
+	storeProvider, err := r.getProvider(store)
 	if err != nil {
 		log.Error(err, "could not get store provider")
 		syncCallsError.With(syncCallsMetricLabels).Inc()
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		return nil, &ctrl.Result{RequeueAfter: requeueAfter}
 	}
 
-	secretClient, err := storeProvider.NewClient(ctx, store, r.Client, req.Namespace)
+	secretClient, err := storeProvider.NewClient(ctx, store, r.Client, externalSecret.Namespace)
 	if err != nil {
 		log.Error(err, "could not get provider client")
 		conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionFalse, esv1alpha1.ConditionReasonSecretSyncedError, err.Error())
-		SetExternalSecretCondition(&externalSecret, *conditionSynced)
-		err = r.Status().Update(ctx, &externalSecret)
+		SetExternalSecretCondition(externalSecret, *conditionSynced)
+		if err := r.Status().Update(ctx, externalSecret); err != nil {
+			log.Error(err, "unable to update status")
+		}
 		syncCallsError.With(syncCallsMetricLabels).Inc()
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		return nil, &ctrl.Result{RequeueAfter: requeueAfter}
 	}
-	secret := defaultSecret(externalSecret)
-	_, err = ctrl.CreateOrUpdate(ctx, r.Client, secret, func() error {
-		err = controllerutil.SetControllerReference(&externalSecret, &secret.ObjectMeta, r.Scheme)
-		if err != nil {
-			return fmt.Errorf("could not set ExternalSecret controller reference: %w", err)
+	// values served from the version cache do not count against the call budget,
+	// keys are resolved first, so the version cache uses the resolved key and
+	// only holds encrypted values
+	secretClient = r.withVersionCache(store, secretClient, withCallBudget(budget, secretClient))
+	secretClient = withDecryption(r.Client, externalSecret.Namespace, secretClient)
+	return withKeyResolution(r.Client, externalSecret.Namespace, secretClient), nil
+}
+
+func (r *Reconciler) getProvider(store esv1alpha1.GenericStore) (provider.Provider, error) {
+	if r.GetProvider != nil {
+		return r.GetProvider(store)
+	}
+	return schema.GetProvider(store)
+}
+
+// errNoSecretStore is returned when Provider data is requested from an
+// ExternalSecret without spec.secretStoreRef.
+var errNoSecretStore = errors.New("spec.secretStoreRef is required to fetch data from a provider")
+
+// noStoreClient is used for ExternalSecrets without a store. They can only
+// render in-cluster values, every attempt to fetch Provider data fails.
+type noStoreClient struct{}
+
+func (noStoreClient) GetSecret(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+	return nil, errNoSecretStore
+}
+
+func (noStoreClient) GetSecretMap(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	return nil, errNoSecretStore
+}
+
+func (noStoreClient) Exists(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	return false, errNoSecretStore
+}
+
+// syncSecret creates or updates the target Secret and its copies in other namespaces.
+// If the write is deferred because of spec.minWriteInterval the remaining wait time is returned.
+func (r *Reconciler) syncSecret(ctx context.Context, secretClient provider.SecretsClient, externalSecret *esv1alpha1.ExternalSecret, templateFrom *templateFromData) (controllerutil.OperationResult, time.Duration, error) {
+	if err := r.checkTargetNamespaces(externalSecret); err != nil {
+		return controllerutil.OperationResultNone, 0, err
+	}
+
+	secret := defaultSecret(*externalSecret)
+	var writeDeferredFor time.Duration
+	var partialErr error
+	var previous map[string][]byte
+	mutate := func() error {
+		existing := secret.DeepCopy()
+		previous = existing.Data
+		err := r.applySecretData(ctx, secret, secretClient, externalSecret, templateFrom)
+		if err != nil && !isPartialSync(externalSecret, err) {
+			return err
 		}
-		data, err := r.getProviderSecretData(ctx, secretClient, &externalSecret)
-		if err != nil {
-			return fmt.Errorf("could not get secret data from provider: %w", err)
+		partialErr = err
+		// fail before the API server rejects the secret with an opaque error
+		if size := secretSize(secret); size > corev1.MaxSecretSize {
+			return &secretTooLargeError{size: size}
 		}
-		// overwrite data
-		for k, v := range data {
-			secret.Data[k] = v
+		// coalesce changes of existing secrets within the minimum write interval
+		wait := writeDeferral(externalSecret, time.Now())
+		if wait > 0 && secret.ResourceVersion != "" && !equality.Semantic.DeepEqual(existing, secret) {
+			existing.DeepCopyInto(secret)
+			writeDeferredFor = wait
 		}
-		err = template.Execute(secret, data)
-		if err != nil {
-			return fmt.Errorf("could not execute template: %w", err)
+		if immutableChanged(existing, secret) {
+			return errImmutableSecretChanged
 		}
 		return nil
-	})
+	}
 
+	recreate, err := r.deleteDriftedSecret(ctx, externalSecret)
 	if err != nil {
-		log.Error(err, "could not reconcile ExternalSecret")
-		conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionFalse, esv1alpha1.ConditionReasonSecretSyncedError, err.Error())
-		SetExternalSecretCondition(&externalSecret, *conditionSynced)
-		err = r.Status().Update(ctx, &externalSecret)
-		if err != nil {
-			log.Error(err, "unable to update status")
+		return controllerutil.OperationResultNone, 0, err
+	}
+	if recreate {
+		// the cache may still hold the deleted secret, so it is created without reading it first
+		if err := mutate(); err != nil {
+			return controllerutil.OperationResultNone, 0, err
 		}
-		syncCallsError.With(syncCallsMetricLabels).Inc()
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		return r.recreateSecret(ctx, externalSecret, secret, partialErr)
 	}
 
-	dur := time.Hour
-	if externalSecret.Spec.RefreshInterval != nil {
-		dur = externalSecret.Spec.RefreshInterval.Duration
+	op, err := ctrl.CreateOrUpdate(ctx, r.Client, secret, mutate)
+	if errors.Is(err, errImmutableSecretChanged) {
+		return r.replaceImmutableSecret(ctx, externalSecret, secret, previous, partialErr)
+	}
+	if err != nil {
+		return op, writeDeferredFor, err
 	}
+	if op == controllerutil.OperationResultUpdated {
+		r.recordValueUpdate(externalSecret, previous, secret.Data)
+	}
+	if err := r.replicateSecret(ctx, externalSecret, secret); err != nil {
+		return op, writeDeferredFor, err
+	}
+	return op, writeDeferredFor, partialErr
+}
 
-	conditionSynced := NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionTrue, esv1alpha1.ConditionReasonSecretSynced, "Secret was synced")
-	SetExternalSecretCondition(&externalSecret, *conditionSynced)
-	externalSecret.Status.RefreshTime = metav1.NewTime(time.Now())
-	err = r.Status().Update(ctx, &externalSecret)
+// replaceImmutableSecret deletes the live immutable Secret, whose data can not be
+// updated, and creates it again with the data of secret.
+func (r *Reconciler) replaceImmutableSecret(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret, secret *corev1.Secret, previous map[string][]byte, partialErr error) (controllerutil.OperationResult, time.Duration, error) {
+	err := r.Delete(ctx, secret, client.Preconditions{UID: &secret.UID, ResourceVersion: &secret.ResourceVersion})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return controllerutil.OperationResultNone, 0, fmt.Errorf("could not delete immutable secret: %w", err)
+	}
+	r.recordValueUpdate(externalSecret, previous, secret.Data)
+	secret.ObjectMeta = metav1.ObjectMeta{
+		Name:            secret.Name,
+		Namespace:       secret.Namespace,
+		Labels:          secret.Labels,
+		Annotations:     secret.Annotations,
+		OwnerReferences: secret.OwnerReferences,
+	}
+	return r.recreateSecret(ctx, externalSecret, secret, partialErr)
+}
+
+// recreateSecret creates the target Secret after it was deleted and copies it to other namespaces.
+func (r *Reconciler) recreateSecret(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret, secret *corev1.Secret, partialErr error) (controllerutil.OperationResult, time.Duration, error) {
+	if err := r.Create(ctx, secret); err != nil {
+		return controllerutil.OperationResultNone, 0, fmt.Errorf("could not recreate secret: %w", err)
+	}
+	if err := r.replicateSecret(ctx, externalSecret, secret); err != nil {
+		return controllerutil.OperationResultCreated, 0, err
+	}
+	return controllerutil.OperationResultCreated, 0, partialErr
+}
+
+// recordValueUpdate counts an update of the target Secret and records an event
+// if its data differs from the data before the write. Updates of labels or
+// annotations only are not counted. The event never contains values.
+func (r *Reconciler) recordValueUpdate(externalSecret *esv1alpha1.ExternalSecret, previous, current map[string][]byte) {
+	changed := changedKeys(previous, current)
+	if changed == 0 {
+		return
+	}
+	externalSecret.Status.SecretValueUpdates++
+	if r.Recorder != nil {
+		r.Recorder.Eventf(externalSecret, corev1.EventTypeNormal, eventReasonSecretValueUpdated,
+			"Secret %q was updated, %d of %d keys changed", externalSecret.Spec.Target.Name, changed, len(current))
+	}
+}
+
+// changedKeys returns the number of keys that were added, removed or changed.
+func changedKeys(previous, current map[string][]byte) int {
+	changed := 0
+	for k, v := range current {
+		if old, ok := previous[k]; !ok || !bytes.Equal(old, v) {
+			changed++
+		}
+	}
+	for k := range previous {
+		if _, ok := current[k]; !ok {
+			changed++
+		}
+	}
+	return changed
+}
+
+// deleteDriftedSecret deletes the target Secret if its immutability differs from
+// spec.target.immutable. Immutability can not be patched, so the Secret has to be
+// created again, which is reported by returning true.
+func (r *Reconciler) deleteDriftedSecret(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) (bool, error) {
+	var live corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{Name: externalSecret.Spec.Target.Name, Namespace: externalSecret.Namespace}, &live)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
 	if err != nil {
-		log.Error(err, "unable to update status")
+		return false, fmt.Errorf("could not get secret: %w", err)
+	}
+	// secrets that are not owned by the ExternalSecret are never deleted
+	if !metav1.IsControlledBy(&live, externalSecret) || isImmutable(&live) == externalSecret.Spec.Target.Immutable {
+		return false, nil
+	}
+	err = r.Delete(ctx, &live, client.Preconditions{UID: &live.UID, ResourceVersion: &live.ResourceVersion})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("could not delete drifted secret: %w", err)
 	}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(externalSecret, corev1.EventTypeNormal, eventReasonDriftCorrected,
+			"Secret %q was recreated to set immutable to %t", live.Name, externalSecret.Spec.Target.Immutable)
+	}
+	return true, nil
+}
 
-	syncCallsTotal.With(syncCallsMetricLabels).Inc()
+// isImmutable returns whether the Secret is marked as immutable.
+func isImmutable(secret *corev1.Secret) bool {
+	return secret.Immutable != nil && *secret.Immutable
+}
 
-	return ctrl.Result{
-		RequeueAfter: dur,
-	}, nil
+// errImmutableSecretChanged stops the update of an existing immutable Secret
+// whose data changed, which the API server would reject.
+var errImmutableSecretChanged = errors.New("data of immutable secret changed")
+
+// immutableChanged returns whether the live Secret is immutable and can not be
+// updated to the desired Secret.
+func immutableChanged(live, desired *corev1.Secret) bool {
+	return live.ResourceVersion != "" && isImmutable(live) &&
+		(!isImmutable(desired) || !equality.Semantic.DeepEqual(live.Data, desired.Data))
+}
+
+// syncedCondition returns the Ready condition of a successful sync.
+// A partial sync is reported with the error that stopped it.
+func syncedCondition(partialErr error) *esv1alpha1.ExternalSecretStatusCondition {
+	if partialErr != nil {
+		return NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionTrue, esv1alpha1.ConditionReasonCallBudgetExceeded, "Secret was synced partially: "+partialErr.Error())
+	}
+	return NewExternalSecretCondition(esv1alpha1.ExternalSecretReady, corev1.ConditionTrue, esv1alpha1.ConditionReasonSecretSynced, "Secret was synced")
+}
+
+// providerErrorReasons maps the errors providers wrap to the reasons of the Ready condition.
+// Other provider errors are reported as SecretSyncedError.
+var providerErrorReasons = []struct {
+	err    error
+	reason string
+}{
+	{err: provider.ErrDisallowedKMSKey, reason: esv1alpha1.ConditionReasonDisallowedKMSKey},
+	{err: provider.ErrAccessDenied, reason: esv1alpha1.ConditionReasonAccessDenied},
+	{err: provider.ErrSecretNotFound, reason: esv1alpha1.ConditionReasonSecretNotFound},
+	{err: provider.ErrDecryptionFailed, reason: esv1alpha1.ConditionReasonProviderDecryptionFailed},
+	{err: provider.ErrThrottled, reason: esv1alpha1.ConditionReasonThrottled},
+}
+
+// syncErrorReason returns the condition reason for an error of syncSecret.
+func syncErrorReason(err error) string {
+	var tooLarge *secretTooLargeError
+	if errors.As(err, &tooLarge) {
+		return esv1alpha1.ConditionReasonSecretTooLarge
+	}
+	for _, r := range providerErrorReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	var notAllowed *namespaceNotAllowedError
+	if errors.As(err, &notAllowed) {
+		return esv1alpha1.ConditionReasonNamespaceNotAllowed
+	}
+	if isCallBudgetExceeded(err) {
+		return esv1alpha1.ConditionReasonCallBudgetExceeded
+	}
+	if isKeyRefError(err) {
+		return esv1alpha1.ConditionReasonInvalidKeyRef
+	}
+	if isDecryptionError(err) {
+		return esv1alpha1.ConditionReasonDecryptionFailed
+	}
+	return esv1alpha1.ConditionReasonSecretSyncedError
+}
+
+// isConfigurationError returns true for errors that are not skipped for optional keys,
+// as they would fail for the required keys the same way.
+func isConfigurationError(err error) bool {
+	return isCallBudgetExceeded(err) || isKeyRefError(err) || isDecryptionError(err)
+}
+
+// requeueInterval returns when the ExternalSecret has to be refreshed next.
+// A deferred write shortens the refresh interval. Completed ExternalSecrets are not refreshed.
+func (r *Reconciler) requeueInterval(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret, writeDeferredFor time.Duration) time.Duration {
+	if isSyncCompleted(externalSecret) {
+		return 0
+	}
+	dur := jitter(r.refreshInterval(ctx, externalSecret), r.RequeueJitter)
+	if writeDeferredFor > 0 && (dur == 0 || writeDeferredFor < dur) {
+		dur = writeDeferredFor
+	}
+	return dur
+}
+
+// refreshInterval returns spec.refreshInterval of the ExternalSecret. If it is not set
+// spec.refreshInterval of the store is used and then defaultRefreshInterval.
+func (r *Reconciler) refreshInterval(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) time.Duration {
+	if externalSecret.Spec.RefreshInterval != nil {
+		return externalSecret.Spec.RefreshInterval.Duration
+	}
+	if externalSecret.Spec.SecretStoreRef.Name == "" {
+		return defaultRefreshInterval
+	}
+	// the store was read by the reconcile already, so it is served from the cache
+	store, err := r.getStore(ctx, externalSecret)
+	if err != nil || store.GetSpec().RefreshInterval == nil {
+		return defaultRefreshInterval
+	}
+	return store.GetSpec().RefreshInterval.Duration
 }
 
 func shouldProcessStore(store esv1alpha1.GenericStore, class string) bool {
@@ -171,9 +629,16 @@ func defaultSecret(es esv1alpha1.ExternalSecret) *corev1.Secret {
 		Data: make(map[string][]byte),
 	}
 
+	if es.Spec.Target.Immutable {
+		immutable := true
+		secret.Immutable = &immutable
+	}
+
 	if es.Spec.Target.Template != nil {
 		secret.Type = es.Spec.Target.Template.Type
-		secret.Data = es.Spec.Target.Template.Data
+		for k, v := range es.Spec.Target.Template.Data {
+			secret.Data[k] = v
+		}
 		secret.ObjectMeta.Labels = es.Spec.Target.Template.Metadata.Labels
 		secret.ObjectMeta.Annotations = es.Spec.Target.Template.Metadata.Annotations
 	}
@@ -181,12 +646,139 @@ func defaultSecret(es esv1alpha1.ExternalSecret) *corev1.Secret {
 	return secret
 }
 
+// applySecretData sets the controller reference and renders the provider data into the secret.
+func (r *Reconciler) applySecretData(ctx context.Context, secret *corev1.Secret, secretClient provider.SecretsClient, externalSecret *esv1alpha1.ExternalSecret, templateFrom *templateFromData) error {
+	err := controllerutil.SetControllerReference(externalSecret, &secret.ObjectMeta, r.Scheme)
+	if err != nil {
+		return fmt.Errorf("could not set ExternalSecret controller reference: %w", err)
+	}
+	data, fetchErr := r.getProviderSecretData(ctx, secretClient, externalSecret)
+	if fetchErr != nil && !isPartialSync(externalSecret, fetchErr) {
+		return fmt.Errorf("could not get secret data from provider: %w", fetchErr)
+	}
+	// referenced templates are handled like inline templates
+	for k, v := range templateFrom.templates {
+		secret.Data[k] = v
+	}
+	// overwrite data
+	for k, v := range data {
+		secret.Data[k] = v
+	}
+	// referenced values are only available to the templates, provider data takes precedence
+	values := utils.Merge(utils.Merge(make(map[string][]byte), templateFrom.values), data)
+	err = template.Execute(secret, values)
+	if err != nil {
+		return fmt.Errorf("could not execute template: %w", err)
+	}
+	if key := externalSecret.Spec.Target.ShellEnvKey; key != "" {
+		if err := addShellEnvKey(secret, key); err != nil {
+			return err
+		}
+	}
+	if externalSecret.Spec.Target.DigestKeys {
+		addDigestKeys(secret)
+	}
+	// the resolved data of a partial sync is applied, the exceeded budget is reported afterwards
+	if fetchErr != nil {
+		return fmt.Errorf("could not get secret data from provider: %w", fetchErr)
+	}
+	return nil
+}
+
+// addDigestKeys writes the SHA-256 digest of every value of an Opaque secret
+// to an additional key. Keys that already have the suffix are digests
+// of a previous reconcile and are updated instead of digested again.
+func addDigestKeys(secret *corev1.Secret) {
+	if secret.Type != "" && secret.Type != corev1.SecretTypeOpaque {
+		return
+	}
+	for k, v := range secret.Data {
+		if strings.HasSuffix(k, digestKeySuffix) {
+			continue
+		}
+		sum := sha256.Sum256(v)
+		secret.Data[k+digestKeySuffix] = []byte(hex.EncodeToString(sum[:]))
+	}
+}
+
+// shellVariableName matches the names POSIX shells accept for variables.
+var shellVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// addShellEnvKey writes all other keys of the secret as single-quoted shell
+// variable assignments to key. Digest keys are left out, they are added afterwards.
+func addShellEnvKey(secret *corev1.Secret, key string) error {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		if k == key || strings.HasSuffix(k, digestKeySuffix) {
+			continue
+		}
+		if !shellVariableName.MatchString(k) {
+			return fmt.Errorf("could not write shell env key %q: %q is not a valid shell variable name", key, k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s=%s\n", k, template.ShellQuote(string(secret.Data[k])))
+	}
+	secret.Data[key] = buf.Bytes()
+	return nil
+}
+
+// secretTooLargeError is returned if the Secret data exceeds corev1.MaxSecretSize.
+type secretTooLargeError struct {
+	size int
+}
+
+func (e *secretTooLargeError) Error() string {
+	return fmt.Sprintf("secret data has %d bytes, which exceeds the limit of %d bytes", e.size, corev1.MaxSecretSize)
+}
+
+// secretSize returns the size of the Secret data the way the API server validates it.
+func secretSize(secret *corev1.Secret) int {
+	size := 0
+	for k, v := range secret.Data {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// writeDeferral returns how long a changed secret has to wait until it may be
+// written again according to spec.minWriteInterval. Zero means it can be written now.
+func writeDeferral(es *esv1alpha1.ExternalSecret, now time.Time) time.Duration {
+	if es.Spec.MinWriteInterval == nil || es.Status.LastWriteTime.IsZero() {
+		return 0
+	}
+	next := es.Status.LastWriteTime.Add(es.Spec.MinWriteInterval.Duration)
+	if !now.Before(next) {
+		return 0
+	}
+	return next.Sub(now)
+}
+
+// jitter randomly changes the duration by up to ±fraction of its value.
+// The fraction is clamped below 1 so the duration never becomes zero or negative.
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if d <= 0 || fraction <= 0 {
+		return d
+	}
+	fraction = math.Min(fraction, math.Nextafter(1, 0))
+	// nolint:gosec // the jitter does not need a cryptographically secure source
+	return d + time.Duration((rand.Float64()*2-1)*fraction*float64(d))
+}
+
 func (r *Reconciler) getStore(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) (esv1alpha1.GenericStore, error) {
+	return r.getStoreByRef(ctx, externalSecret.Namespace, externalSecret.Spec.SecretStoreRef)
+}
+
+// getStoreByRef returns the store storeRef points to. SecretStores are read from namespace.
+func (r *Reconciler) getStoreByRef(ctx context.Context, namespace string, storeRef esv1alpha1.SecretStoreRef) (esv1alpha1.GenericStore, error) {
 	ref := types.NamespacedName{
-		Name: externalSecret.Spec.SecretStoreRef.Name,
+		Name: storeRef.Name,
 	}
 
-	if externalSecret.Spec.SecretStoreRef.Kind == esv1alpha1.ClusterSecretStoreKind {
+	if storeRef.Kind == esv1alpha1.ClusterSecretStoreKind {
 		var store esv1alpha1.ClusterSecretStore
 		err := r.Get(ctx, ref, &store)
 		if err != nil {
@@ -196,7 +788,7 @@ func (r *Reconciler) getStore(ctx context.Context, externalSecret *esv1alpha1.Ex
 		return &store, nil
 	}
 
-	ref.Namespace = externalSecret.Namespace
+	ref.Namespace = namespace
 
 	var store esv1alpha1.SecretStore
 	err := r.Get(ctx, ref, &store)
@@ -206,33 +798,662 @@ func (r *Reconciler) getStore(ctx context.Context, externalSecret *esv1alpha1.Ex
 	return &store, nil
 }
 
+// getProviderSecretData fetches the data of the ExternalSecret from the provider.
+// On errors the data that was resolved until then is returned as well.
 func (r *Reconciler) getProviderSecretData(ctx context.Context, providerClient provider.SecretsClient, externalSecret *esv1alpha1.ExternalSecret) (map[string][]byte, error) {
 	providerData := make(map[string][]byte)
 
 	for _, remoteRef := range externalSecret.Spec.DataFrom {
-		secretMap, err := providerClient.GetSecretMap(ctx, remoteRef)
+		secretMap, err := clientForEntry(providerClient, remoteRef.SecretStoreRef).GetSecretMap(ctx, remoteRef.ExternalSecretDataRemoteRef)
 		if err != nil {
-			return nil, fmt.Errorf("key %q from ExternalSecret %q: %w", remoteRef.Key, externalSecret.Name, err)
+			return providerData, fmt.Errorf("key %q from ExternalSecret %q: %w", remoteRef.Key, externalSecret.Name, err)
+		}
+
+		secretMap, err = processDataFromKeys(secretMap, remoteRef)
+		if err != nil {
+			return providerData, fmt.Errorf("key %q from ExternalSecret %q: %w", remoteRef.Key, externalSecret.Name, err)
 		}
 
 		providerData = utils.Merge(providerData, secretMap)
 	}
 
+	// the skipped keys are reported with the status update of the reconcile
+	externalSecret.Status.SkippedKeys = nil
 	for _, secretRef := range externalSecret.Spec.Data {
-		secretData, err := providerClient.GetSecret(ctx, secretRef.RemoteRef)
+		secretData, err := getSecretOrDefault(ctx, clientForEntry(providerClient, secretRef.SecretStoreRef), secretRef)
+		// configuration errors are not skipped
+		if err != nil && secretRef.Optional && !isConfigurationError(err) {
+			r.Log.V(1).Info("skipping optional key", "ExternalSecret", client.ObjectKeyFromObject(externalSecret), "key", secretRef.SecretKey, "error", err.Error())
+			externalSecret.Status.SkippedKeys = append(externalSecret.Status.SkippedKeys, secretRef.SecretKey)
+			continue
+		}
 		if err != nil {
-			return nil, fmt.Errorf("key %q from ExternalSecret %q: %w", secretRef.RemoteRef.Key, externalSecret.Name, err)
+			return providerData, fmt.Errorf("key %q from ExternalSecret %q: %w", secretRef.RemoteRef.Key, externalSecret.Name, err)
 		}
 
 		providerData[secretRef.SecretKey] = secretData
 	}
 
+	for _, assembly := range externalSecret.Spec.Target.Assemble {
+		var assembled []byte
+		for i, remoteRef := range assembly.RemoteRefs {
+			chunk, err := providerClient.GetSecret(ctx, remoteRef)
+			if err != nil {
+				return providerData, fmt.Errorf("chunk %d (key %q) of secret key %q from ExternalSecret %q: %w", i, remoteRef.Key, assembly.SecretKey, externalSecret.Name, err)
+			}
+			assembled = append(assembled, chunk...)
+		}
+
+		providerData[assembly.SecretKey] = assembled
+	}
+
+	for _, composition := range externalSecret.Spec.Target.Compose {
+		composed, err := composeSecret(ctx, providerClient, composition)
+		if err != nil {
+			return providerData, fmt.Errorf("secret key %q from ExternalSecret %q: %w", composition.SecretKey, externalSecret.Name, err)
+		}
+
+		providerData[composition.SecretKey] = composed
+	}
+
 	return providerData, nil
 }
 
+// getSecretOrDefault fetches the value of a data entry. The default of the entry
+// is used if the property of its remote ref does not exist.
+func getSecretOrDefault(ctx context.Context, providerClient provider.SecretsClient, secretRef esv1alpha1.ExternalSecretData) ([]byte, error) {
+	secretData, err := providerClient.GetSecret(ctx, secretRef.RemoteRef)
+	if secretRef.Default != nil && errors.Is(err, provider.ErrPropertyNotFound) {
+		return []byte(*secretRef.Default), nil
+	}
+	return secretData, err
+}
+
+// processDataFromKeys filters and transforms the keys of a dataFrom entry
+// and adds the JSON key if requested.
+func processDataFromKeys(secretMap map[string][]byte, remoteRef esv1alpha1.ExternalSecretDataFromRemoteRef) (map[string][]byte, error) {
+	secretMap, err := filterDataFromKeys(secretMap, remoteRef)
+	if err != nil {
+		return nil, err
+	}
+	secretMap, err = stripDataFromKeyPrefix(secretMap, remoteRef)
+	if err != nil {
+		return nil, err
+	}
+	secretMap, err = transformDataFromKeys(secretMap, remoteRef.KeyTransform)
+	if err != nil {
+		return nil, err
+	}
+	if remoteRef.JSONKey == "" {
+		return secretMap, nil
+	}
+	return addJSONKey(secretMap, remoteRef.JSONKey)
+}
+
+// filterDataFromKeys keeps the keys matching an include expression of the dataFrom entry
+// and removes the keys matching an exclude expression afterwards.
+func filterDataFromKeys(secretMap map[string][]byte, remoteRef esv1alpha1.ExternalSecretDataFromRemoteRef) (map[string][]byte, error) {
+	if len(remoteRef.Include) == 0 && len(remoteRef.Exclude) == 0 {
+		return secretMap, nil
+	}
+	include, err := compileKeyExpressions(remoteRef.Include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include expression: %w", err)
+	}
+	exclude, err := compileKeyExpressions(remoteRef.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude expression: %w", err)
+	}
+
+	filtered := make(map[string][]byte, len(secretMap))
+	for k, v := range secretMap {
+		if len(include) > 0 && !matchesAny(include, k) {
+			continue
+		}
+		if matchesAny(exclude, k) {
+			continue
+		}
+		filtered[k] = v
+	}
+	return filtered, nil
+}
+
+// stripDataFromKeyPrefix removes the prefix of the dataFrom entry from every key.
+// Keys without the prefix are kept or dropped, keys that end up with the same name are rejected.
+func stripDataFromKeyPrefix(secretMap map[string][]byte, remoteRef esv1alpha1.ExternalSecretDataFromRemoteRef) (map[string][]byte, error) {
+	if remoteRef.StripPrefix == "" {
+		return secretMap, nil
+	}
+	stripped := make(map[string][]byte, len(secretMap))
+	origins := make(map[string]string, len(secretMap))
+	for _, k := range sortedKeys(secretMap) {
+		newKey := strings.TrimPrefix(k, remoteRef.StripPrefix)
+		if newKey == k && remoteRef.DropUnprefixedKeys {
+			continue
+		}
+		if newKey == "" {
+			return nil, fmt.Errorf("key %q is empty without the prefix %q", k, remoteRef.StripPrefix)
+		}
+		if origin, ok := origins[newKey]; ok {
+			return nil, fmt.Errorf("keys %q and %q both become %q without the prefix %q", origin, k, newKey, remoteRef.StripPrefix)
+		}
+		origins[newKey] = k
+		stripped[newKey] = secretMap[k]
+	}
+	return stripped, nil
+}
+
+// transformDataFromKeys applies the transformations in order to every key.
+// Keys that end up with the same name are rejected.
+func transformDataFromKeys(secretMap map[string][]byte, transforms []esv1alpha1.KeyTransform) (map[string][]byte, error) {
+	if len(transforms) == 0 {
+		return secretMap, nil
+	}
+	transformed := make(map[string][]byte, len(secretMap))
+	origins := make(map[string]string, len(secretMap))
+	for _, k := range sortedKeys(secretMap) {
+		newKey, err := transformKey(k, transforms)
+		if err != nil {
+			return nil, err
+		}
+		if origin, ok := origins[newKey]; ok {
+			return nil, fmt.Errorf("keys %q and %q both transform to %q", origin, k, newKey)
+		}
+		origins[newKey] = k
+		transformed[newKey] = secretMap[k]
+	}
+	return transformed, nil
+}
+
+// sortedKeys returns the keys of the secret map in sorted order,
+// so collisions are always reported the same way.
+func sortedKeys(secretMap map[string][]byte) []string {
+	keys := make([]string, 0, len(secretMap))
+	for k := range secretMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func transformKey(key string, transforms []esv1alpha1.KeyTransform) (string, error) {
+	for _, transform := range transforms {
+		switch transform {
+		case esv1alpha1.KeyTransformToUpper:
+			key = strings.ToUpper(key)
+		case esv1alpha1.KeyTransformToLower:
+			key = strings.ToLower(key)
+		case esv1alpha1.KeyTransformCamelToSnake:
+			key = camelToSnake(key)
+		default:
+			return "", fmt.Errorf("unknown key transform %q", transform)
+		}
+	}
+	return key, nil
+}
+
+// camelToSnake inserts an underscore at every word boundary of a camelCase key
+// and converts it to lower case. Acronyms are kept together, so
+// HTTPServerURL becomes http_server_url.
+func camelToSnake(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// addJSONKey stores the secret map serialized as JSON object under the given key.
+func addJSONKey(secretMap map[string][]byte, jsonKey string) (map[string][]byte, error) {
+	if _, ok := secretMap[jsonKey]; ok {
+		return nil, fmt.Errorf("json key %q collides with a key of the secret", jsonKey)
+	}
+	kv := make(map[string]string, len(secretMap))
+	out := make(map[string][]byte, len(secretMap)+1)
+	for k, v := range secretMap {
+		// the encoder replaces invalid bytes, so different binary values would serialize the same
+		if !utf8.Valid(v) {
+			return nil, fmt.Errorf("value of key %q is not valid UTF-8 and can not be serialized as json", k)
+		}
+		kv[k] = string(v)
+		out[k] = v
+	}
+	// keys are sorted by the encoder, so the output only depends on the data
+	// and not on the order the provider returned the keys in
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(kv); err != nil {
+		return nil, fmt.Errorf("could not serialize secret as json: %w", err)
+	}
+	out[jsonKey] = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	return out, nil
+}
+
+// keyRegexpPrefix marks include and exclude entries that are regular expressions.
+const keyRegexpPrefix = "regexp:"
+
+// compileKeyExpressions compiles the expressions so that each one has to match a whole key.
+// Entries without keyRegexpPrefix are key names that only match that exact key.
+func compileKeyExpressions(expressions []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expr := range expressions {
+		if strings.HasPrefix(expr, keyRegexpPrefix) {
+			expr = strings.TrimPrefix(expr, keyRegexpPrefix)
+		} else {
+			expr = regexp.QuoteMeta(expr)
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchesAny(expressions []*regexp.Regexp, key string) bool {
+	for _, re := range expressions {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// templateFromData holds the keys referenced by spec.target.template.templateFrom.
+type templateFromData struct {
+	// templates are keyed by the Secret key they are rendered to.
+	templates map[string][]byte
+	// values are available to the templates like Provider data.
+	values map[string][]byte
+}
+
+// getTemplateFrom returns the templates and values referenced by the target template.
+func (r *Reconciler) getTemplateFrom(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) (*templateFromData, error) {
+	templateFrom := &templateFromData{
+		templates: make(map[string][]byte),
+		values:    make(map[string][]byte),
+	}
+	if externalSecret.Spec.Target.Template == nil {
+		return templateFrom, nil
+	}
+
+	for _, tplFrom := range externalSecret.Spec.Target.Template.TemplateFrom {
+		items, err := r.getTemplateFromItems(ctx, externalSecret.Namespace, tplFrom)
+		if err != nil {
+			return nil, err
+		}
+		target := templateFrom.templates
+		if tplFrom.Usage == esv1alpha1.TemplateFromUsageValues {
+			target = templateFrom.values
+		}
+		for k, v := range items {
+			target[k] = v
+		}
+	}
+
+	return templateFrom, nil
+}
+
+// getTemplateFromItems returns the referenced keys of the ConfigMap or Secret of a templateFrom entry.
+func (r *Reconciler) getTemplateFromItems(ctx context.Context, namespace string, tplFrom esv1alpha1.TemplateFrom) (map[string][]byte, error) {
+	items := make(map[string][]byte)
+	if tplFrom.ConfigMap != nil {
+		ref := types.NamespacedName{
+			Name:      tplFrom.ConfigMap.Name,
+			Namespace: namespace,
+		}
+		var configMap corev1.ConfigMap
+		err := r.Get(ctx, ref, &configMap)
+		if err != nil {
+			return nil, fmt.Errorf("could not get template ConfigMap %q: %w", ref.Name, err)
+		}
+		for _, item := range tplFrom.ConfigMap.Items {
+			value, ok := configMap.Data[item.Key]
+			if !ok {
+				return nil, fmt.Errorf("key %q does not exist in template ConfigMap %q", item.Key, ref.Name)
+			}
+			items[item.Key] = []byte(value)
+		}
+	}
+	if tplFrom.Secret != nil {
+		ref := types.NamespacedName{
+			Name:      tplFrom.Secret.Name,
+			Namespace: namespace,
+		}
+		var secret corev1.Secret
+		err := r.Get(ctx, ref, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("could not get template Secret %q: %w", ref.Name, err)
+		}
+		for _, item := range tplFrom.Secret.Items {
+			value, ok := secret.Data[item.Key]
+			if !ok {
+				return nil, fmt.Errorf("key %q does not exist in template Secret %q", item.Key, ref.Name)
+			}
+			items[item.Key] = value
+		}
+	}
+	return items, nil
+}
+
+// checkDependencies verifies that the ExternalSecrets of spec.dependsOn are synced.
+// If not, the condition reason and the cause are returned.
+func (r *Reconciler) checkDependencies(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) (string, error) {
+	if len(externalSecret.Spec.DependsOn) == 0 {
+		return "", nil
+	}
+	cycle, err := r.findDependencyCycle(ctx, externalSecret)
+	if err != nil {
+		return esv1alpha1.ConditionReasonWaitingForDependency, err
+	}
+	if cycle != nil {
+		return esv1alpha1.ConditionReasonDependencyCycle, fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	for _, name := range externalSecret.Spec.DependsOn {
+		var dependency esv1alpha1.ExternalSecret
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: externalSecret.Namespace}, &dependency)
+		if err != nil {
+			return esv1alpha1.ConditionReasonWaitingForDependency, fmt.Errorf("waiting for ExternalSecret %q: %w", name, err)
+		}
+		cond := GetExternalSecretCondition(dependency.Status, esv1alpha1.ExternalSecretReady)
+		if cond == nil || cond.Status != corev1.ConditionTrue {
+			return esv1alpha1.ConditionReasonWaitingForDependency, fmt.Errorf("waiting for ExternalSecret %q to be synced", name)
+		}
+	}
+	return "", nil
+}
+
+// findDependencyCycle follows spec.dependsOn and returns the path of
+// ExternalSecret names if it leads back to the given ExternalSecret.
+// ExternalSecrets that do not exist yet are skipped.
+func (r *Reconciler) findDependencyCycle(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) ([]string, error) {
+	visited := make(map[string]bool)
+	var visit func(path []string, dependsOn []string) ([]string, error)
+	visit = func(path []string, dependsOn []string) ([]string, error) {
+		for _, name := range dependsOn {
+			next := append(append([]string{}, path...), name)
+			if name == externalSecret.Name {
+				return next, nil
+			}
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			var dependency esv1alpha1.ExternalSecret
+			err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: externalSecret.Namespace}, &dependency)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("could not get ExternalSecret %q: %w", name, err)
+			}
+			cycle, err := visit(next, dependency.Spec.DependsOn)
+			if err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+	return visit([]string{externalSecret.Name}, externalSecret.Spec.DependsOn)
+}
+
+// findDependentExternalSecrets maps an ExternalSecret to the ExternalSecrets
+// in the same namespace that depend on it.
+func (r *Reconciler) findDependentExternalSecrets(obj client.Object) []reconcile.Request {
+	var externalSecrets esv1alpha1.ExternalSecretList
+	err := r.List(context.Background(), &externalSecrets, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, "could not list ExternalSecrets", "ExternalSecret", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range externalSecrets.Items {
+		es := &externalSecrets.Items[i]
+		for _, name := range es.Spec.DependsOn {
+			if name == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: es.Name, Namespace: es.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// findExternalSecretsForConfigMap maps a ConfigMap to the ExternalSecrets
+// in the same namespace that use it as template source or read keys from it
+// and to the ExternalSecrets whose store reads the region from it.
+func (r *Reconciler) findExternalSecretsForConfigMap(obj client.Object) []reconcile.Request {
+	var externalSecrets esv1alpha1.ExternalSecretList
+	err := r.List(context.Background(), &externalSecrets, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, "could not list ExternalSecrets", "ConfigMap", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range externalSecrets.Items {
+		es := &externalSecrets.Items[i]
+		if referencesTemplateConfigMap(es, obj.GetName()) || referencesKeyFrom(es, obj.GetName(), true) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: es.Name, Namespace: es.Namespace},
+			})
+		}
+	}
+	return append(requests, r.findExternalSecretsForRegionConfigMap(obj)...)
+}
+
+// findExternalSecretsForRegionConfigMap returns a reconcile request for every ExternalSecret
+// whose store reads its region from the ConfigMap.
+func (r *Reconciler) findExternalSecretsForRegionConfigMap(obj client.Object) []reconcile.Request {
+	return r.findExternalSecretsForStoreReference(obj, "ConfigMap", readsRegionFrom)
+}
+
+// findExternalSecretsForCredentialSecret returns a reconcile request for every ExternalSecret
+// whose store reads its credentials from the Secret, so rotated credentials are used immediately.
+func (r *Reconciler) findExternalSecretsForCredentialSecret(obj client.Object) []reconcile.Request {
+	return r.findExternalSecretsForStoreReference(obj, "Secret", readsCredentialsFrom)
+}
+
+// findExternalSecretsForStoreReference returns a reconcile request for every ExternalSecret
+// whose SecretStore or ClusterSecretStore references the object.
+func (r *Reconciler) findExternalSecretsForStoreReference(obj client.Object, kind string, references func(*esv1alpha1.SecretStoreSpec, client.Object, bool) bool) []reconcile.Request {
+	ctx := context.Background()
+	var stores esv1alpha1.SecretStoreList
+	err := r.List(ctx, &stores, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, "could not list SecretStores", kind, client.ObjectKeyFromObject(obj))
+		return nil
+	}
+	var clusterStores esv1alpha1.ClusterSecretStoreList
+	err = r.List(ctx, &clusterStores)
+	if err != nil {
+		r.Log.Error(err, "could not list ClusterSecretStores", kind, client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	storeNames := make(map[string]bool)
+	for i := range stores.Items {
+		if references(&stores.Items[i].Spec, obj, false) {
+			storeNames[stores.Items[i].Name] = true
+		}
+	}
+	clusterStoreNames := make(map[string]bool)
+	for i := range clusterStores.Items {
+		if references(&clusterStores.Items[i].Spec, obj, true) {
+			clusterStoreNames[clusterStores.Items[i].Name] = true
+		}
+	}
+	if len(storeNames) == 0 && len(clusterStoreNames) == 0 {
+		return nil
+	}
+
+	// ClusterSecretStores are used by ExternalSecrets of all namespaces
+	var opts []client.ListOption
+	if len(clusterStoreNames) == 0 {
+		opts = append(opts, client.InNamespace(obj.GetNamespace()))
+	}
+	var externalSecrets esv1alpha1.ExternalSecretList
+	err = r.List(ctx, &externalSecrets, opts...)
+	if err != nil {
+		r.Log.Error(err, "could not list ExternalSecrets", kind, client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range externalSecrets.Items {
+		es := &externalSecrets.Items[i]
+		if usesStore(es, obj.GetNamespace(), storeNames, clusterStoreNames) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: es.Name, Namespace: es.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// readsRegionFrom returns true if the store reads its region from the ConfigMap.
+// ClusterSecretStores have to reference the namespace of the ConfigMap explicitly.
+func readsRegionFrom(spec *esv1alpha1.SecretStoreSpec, configMap client.Object, clusterScoped bool) bool {
+	if spec.Provider == nil || spec.Provider.AWS == nil || spec.Provider.AWS.RegionFrom == nil {
+		return false
+	}
+	ref := spec.Provider.AWS.RegionFrom.ConfigMapKeyRef
+	if ref.Name != configMap.GetName() {
+		return false
+	}
+	if clusterScoped {
+		return ref.Namespace != nil && *ref.Namespace == configMap.GetNamespace()
+	}
+	return true
+}
+
+// readsCredentialsFrom returns true if the store reads credentials from the Secret.
+// ClusterSecretStores have to reference the namespace of the Secret explicitly.
+func readsCredentialsFrom(spec *esv1alpha1.SecretStoreSpec, secret client.Object, clusterScoped bool) bool {
+	for _, ref := range credentialSecretRefs(spec) {
+		if ref.Name != secret.GetName() {
+			continue
+		}
+		if !clusterScoped || (ref.Namespace != nil && *ref.Namespace == secret.GetNamespace()) {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialSecretRefs returns the Secret keys the provider of the store authenticates with.
+func credentialSecretRefs(spec *esv1alpha1.SecretStoreSpec) []esmeta.SecretKeySelector {
+	var refs []esmeta.SecretKeySelector
+	if spec.Provider == nil {
+		return refs
+	}
+	if aws := spec.Provider.AWS; aws != nil {
+		if aws.Auth != nil {
+			refs = append(refs, aws.Auth.SecretRef.AccessKeyID, aws.Auth.SecretRef.SecretAccessKey)
+		}
+		for _, method := range aws.AuthMethods {
+			if method.SecretRef != nil {
+				refs = append(refs, method.SecretRef.AccessKeyID, method.SecretRef.SecretAccessKey)
+			}
+		}
+	}
+	if vault := spec.Provider.Vault; vault != nil {
+		if vault.Auth.TokenSecretRef != nil {
+			refs = append(refs, *vault.Auth.TokenSecretRef)
+		}
+		if vault.Auth.AppRole != nil {
+			refs = append(refs, vault.Auth.AppRole.SecretRef)
+		}
+		if vault.Auth.Kubernetes != nil && vault.Auth.Kubernetes.SecretRef != nil {
+			refs = append(refs, *vault.Auth.Kubernetes.SecretRef)
+		}
+	}
+	return refs
+}
+
+func referencesTemplateConfigMap(es *esv1alpha1.ExternalSecret, name string) bool {
+	if es.Spec.Target.Template == nil {
+		return false
+	}
+	for _, tplFrom := range es.Spec.Target.Template.TemplateFrom {
+		if tplFrom.ConfigMap != nil && tplFrom.ConfigMap.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findExternalSecretsForSecret returns a reconcile request for every ExternalSecret
+// in the same namespace that uses the Secret as template source, reads keys or
+// decryption identities from it, and for every ExternalSecret whose store
+// authenticates with it.
+func (r *Reconciler) findExternalSecretsForSecret(obj client.Object) []reconcile.Request {
+	var externalSecrets esv1alpha1.ExternalSecretList
+	err := r.List(context.Background(), &externalSecrets, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, "could not list ExternalSecrets", "Secret", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range externalSecrets.Items {
+		es := &externalSecrets.Items[i]
+		if referencesTemplateSecret(es, obj.GetName()) || referencesKeyFrom(es, obj.GetName(), false) || referencesIdentitySecret(es, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: es.Name, Namespace: es.Namespace},
+			})
+		}
+	}
+	return append(requests, r.findExternalSecretsForCredentialSecret(obj)...)
+}
+
+func referencesTemplateSecret(es *esv1alpha1.ExternalSecret, name string) bool {
+	if es.Spec.Target.Template == nil {
+		return false
+	}
+	for _, tplFrom := range es.Spec.Target.Template.TemplateFrom {
+		if tplFrom.Secret != nil && tplFrom.Secret.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// specChangedPredicate ignores updates of the status and of metadata that does not
+// affect the Secret, e.g. the status updates of the controller itself, so they do not
+// cause another fetch from the provider. Labels and annotations are copied to the Secret
+// and pause the ExternalSecret, so their changes are reconciled like spec changes.
+var specChangedPredicate = predicate.Or(
+	predicate.GenerationChangedPredicate{},
+	predicate.LabelChangedPredicate{},
+	predicate.AnnotationChangedPredicate{},
+	predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectNew.GetDeletionTimestamp() != nil
+		},
+	},
+)
+
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&esv1alpha1.ExternalSecret{}).
+		For(&esv1alpha1.ExternalSecret{}, builder.WithPredicates(specChangedPredicate)).
 		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.findExternalSecretsForConfigMap)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.findExternalSecretsForSecret)).
+		Watches(&source.Kind{Type: &esv1alpha1.ExternalSecret{}}, handler.EnqueueRequestsFromMapFunc(r.findDependentExternalSecrets)).
 		Complete(r)
 }
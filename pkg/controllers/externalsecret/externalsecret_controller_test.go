@@ -15,19 +15,27 @@ package externalsecret
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	dto "github.com/prometheus/client_model/go"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
 	"github.com/external-secrets/external-secrets/pkg/provider"
 	"github.com/external-secrets/external-secrets/pkg/provider/fake"
 	"github.com/external-secrets/external-secrets/pkg/provider/schema"
@@ -35,9 +43,11 @@ import (
 
 var (
 	fakeProvider *fake.Client
-	metric       dto.Metric
-	timeout      = time.Second * 30
-	interval     = time.Millisecond * 250
+	// vaultProvider serves SecretStores with a Vault provider
+	vaultProvider *fake.Client
+	metric        dto.Metric
+	timeout       = time.Second * 30
+	interval      = time.Millisecond * 250
 )
 
 var _ = Describe("ExternalSecret controller", func() {
@@ -302,17 +312,3068 @@ var _ = Describe("ExternalSecret controller", func() {
 				es.Spec.Target.Template.Metadata.Annotations))
 		})
 
+		It("should render the template from a referenced ConfigMap", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			const templateSecretKey = "tplkey"
+			const templateConfigMapName = "template-cm"
+			configMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      templateConfigMapName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string]string{
+					templateSecretKey: "{{ .targetProperty | toString | upper }}",
+				},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).Should(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Template: &esv1alpha1.ExternalSecretTemplate{
+							TemplateFrom: []esv1alpha1.TemplateFrom{
+								{
+									ConfigMap: &esv1alpha1.TemplateRef{
+										Name: templateConfigMapName,
+										Items: []esv1alpha1.TemplateRefItem{
+											{
+												Key: templateSecretKey,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				v1 := syncedSecret.Data[targetProp]
+				v2 := syncedSecret.Data[templateSecretKey]
+				return string(v1) == secretVal && string(v2) == "SOMEVALUE" // templated
+			}, timeout, interval).Should(BeTrue())
+
+			By("updating the referenced ConfigMap")
+			configMap.Data[templateSecretKey] = "{{ .targetProperty | toString | lower }}"
+			Expect(k8sClient.Update(ctx, configMap)).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[templateSecretKey]) == "somevalue"
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should set an error condition when the template ConfigMap does not exist", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Template: &esv1alpha1.ExternalSecretTemplate{
+							TemplateFrom: []esv1alpha1.TemplateFrom{
+								{
+									ConfigMap: &esv1alpha1.TemplateRef{
+										Name: "configmapshouldnotexist",
+										Items: []esv1alpha1.TemplateRefItem{
+											{
+												Key: "tplkey",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonTemplateRefError {
+					return false
+				}
+				return true
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should render a secret from values of referenced ConfigMaps without a store", func() {
+			ctx := context.Background()
+			const targetProp = "url"
+			hostConfigMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "values-host",
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string]string{
+					"host": "db.example.com",
+				},
+			}
+			portConfigMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "values-port",
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string]string{
+					"port": "5432",
+				},
+			}
+			Expect(k8sClient.Create(ctx, hostConfigMap)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, portConfigMap)).Should(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Template: &esv1alpha1.ExternalSecretTemplate{
+							Data: map[string][]byte{
+								targetProp: []byte("postgres://{{ .host | toString }}:{{ .port | toString }}"),
+							},
+							TemplateFrom: []esv1alpha1.TemplateFrom{
+								{
+									ConfigMap: &esv1alpha1.TemplateRef{
+										Name:  hostConfigMap.Name,
+										Items: []esv1alpha1.TemplateRefItem{{Key: "host"}},
+									},
+									Usage: esv1alpha1.TemplateFromUsageValues,
+								},
+								{
+									ConfigMap: &esv1alpha1.TemplateRef{
+										Name:  portConfigMap.Name,
+										Items: []esv1alpha1.TemplateRefItem{{Key: "port"}},
+									},
+									Usage: esv1alpha1.TemplateFromUsageValues,
+								},
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret(nil, fmt.Errorf("the provider must not be called"))
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+			// the values are only used by the template
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				targetProp: []byte("postgres://db.example.com:5432"),
+			}))
+		})
+
+		It("should set an error condition when a template value source does not exist", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Template: &esv1alpha1.ExternalSecretTemplate{
+							Data: map[string][]byte{
+								"password": []byte("{{ .password | toString }}"),
+							},
+							TemplateFrom: []esv1alpha1.TemplateFrom{
+								{
+									Secret: &esv1alpha1.TemplateRef{
+										Name:  "secretshouldnotexist",
+										Items: []esv1alpha1.TemplateRefItem{{Key: "password"}},
+									},
+									Usage: esv1alpha1.TemplateFromUsageValues,
+								},
+							},
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonTemplateRefError {
+					return false
+				}
+				return strings.Contains(cond.Message, "secretshouldnotexist")
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should skip missing optional keys and sync the required keys", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "username",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "username"},
+						},
+						{
+							SecretKey: "password",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "password"},
+						},
+						{
+							SecretKey: "comment",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "comment"},
+							Optional:  true,
+						},
+						{
+							SecretKey: "email",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "email"},
+							Optional:  true,
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("secret"),
+				"email":    []byte("admin@example.com"),
+			})
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionTrue
+			}, timeout, interval).Should(BeTrue())
+			Expect(createdES.Status.SkippedKeys).To(Equal([]string{"comment"}))
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, secretLookupKey, syncedSecret)).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("secret"),
+				"email":    []byte("admin@example.com"),
+			}))
+		})
+
+		It("should set an error condition when a required key is missing next to optional keys", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "username",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "username"},
+						},
+						{
+							SecretKey: "comment",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "comment"},
+							Optional:  true,
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{
+				"comment": []byte("some comment"),
+			})
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretSyncedError {
+					return false
+				}
+				return strings.Contains(cond.Message, "username")
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should use the default of a data entry if the property does not exist", func() {
+			ctx := context.Background()
+			defaultPort := "5432"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "port",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "db", Property: "port"},
+							Default:   &defaultPort,
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret(nil, fmt.Errorf("%w: key port does not exist in secret db", provider.ErrPropertyNotFound))
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"port": []byte(defaultPort),
+			}))
+		})
+
+		It("should fetch the key read from a referenced ConfigMap", func() {
+			ctx := context.Background()
+			const keyConfigMapName = "key-cm"
+			configMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      keyConfigMapName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string]string{
+					"secretName": "prod/db/password\n",
+				},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).Should(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "password",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								KeyFrom: &esv1alpha1.RemoteKeySource{
+									ConfigMapKeyRef: &esmeta.ConfigMapKeySelector{
+										Name: keyConfigMapName,
+										Key:  "secretName",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{
+				"prod/db/password":    []byte("prod-secret"),
+				"staging/db/password": []byte("staging-secret"),
+			})
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data["password"]) == "prod-secret"
+			}, timeout, interval).Should(BeTrue())
+
+			By("updating the referenced ConfigMap")
+			configMap.Data["secretName"] = "staging/db/password"
+			Expect(k8sClient.Update(ctx, configMap)).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data["password"]) == "staging-secret"
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should set an InvalidKeyRef condition when the referenced key is empty", func() {
+			ctx := context.Background()
+			const keyConfigMapName = "key-cm"
+			configMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      keyConfigMapName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string]string{
+					"secretName": " ",
+				},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).Should(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "password",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								KeyFrom: &esv1alpha1.RemoteKeySource{
+									ConfigMapKeyRef: &esmeta.ConfigMapKeySelector{
+										Name: keyConfigMapName,
+										Key:  "secretName",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte("someValue"), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonInvalidKeyRef
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should decrypt age encrypted values with identities from a Secret", func() {
+			ctx := context.Background()
+			// encrypted with age for the identity below
+			const encrypted = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSAxdjBmT3kxeC9XdFdSZy9O
+aDNiTERJOTU4VlNQZ3F6cUNRazVtSmRBakNFCmtVM29Lck9MRHk2MWhDd2UrMkxT
+V3IyazZ6bmNScDVMbVFycFNqd0lsVm8KLS0tIHZhUERVQUtHd2V1WlhGZlpYWnNP
+bUdQdHdHcG1OQmlFTSt6RGxBRTNIM0kKN/hsuQeHOMNw8H6NO1g7raWqycqeFOLM
+FBVcNCfB2PHu1NesQAUbFaURsm/8QEEu8cJwqleGBvXulsc5RFuY9Rg=
+-----END AGE ENCRYPTED FILE-----
+`
+			identitySecret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "age-key",
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string][]byte{
+					"keys.txt": []byte("AGE-SECRET-KEY-1403GTPWGJJ0DS4QGWZGNUHRD2TMYHT96CL4JVQTEZDSTF9A66RNS7SJNH6\n"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, identitySecret)).Should(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "dsn",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "encrypted-dsn",
+								Decrypt: &esv1alpha1.RemoteRefDecryption{
+									Age: &esv1alpha1.AgeDecryption{
+										IdentitySecretRef: &esmeta.SecretKeySelector{
+											Name: "age-key",
+											Key:  "keys.txt",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(encrypted), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data["dsn"]) == "postgres://app:s3cr3t@db:5432/app"
+			}, timeout, interval).Should(BeTrue())
+		})
+
 		It("should refresh secret value", func() {
 			ctx := context.Background()
-			const targetProp = "targetProperty"
-			const secretVal = "someValue"
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				v := syncedSecret.Data[targetProp]
+				return string(v) == secretVal
+			}, timeout, interval).Should(BeTrue())
+
+			newValue := "NEW VALUE"
+			fakeProvider.WithGetSecret([]byte(newValue), nil)
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				v := syncedSecret.Data[targetProp]
+				return string(v) == newValue
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should record an event when the secret value changes and not on a no-op refresh", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+			valueUpdatedEvents := func() int {
+				events := &v1.EventList{}
+				Expect(k8sClient.List(ctx, events, client.InNamespace(ExternalSecretNamespace))).Should(Succeed())
+				count := 0
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == ExternalSecretName && event.Reason == "SecretValueUpdated" {
+						count += int(event.Count)
+					}
+				}
+				return count
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionTrue
+			}, timeout, interval).Should(BeTrue())
+
+			By("refreshing without a change")
+			firstRefresh := createdES.Status.RefreshTime
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				return createdES.Status.RefreshTime.After(firstRefresh.Time)
+			}, timeout, interval).Should(BeTrue())
+			Expect(createdES.Status.SecretValueUpdates).To(BeZero())
+			Expect(valueUpdatedEvents()).To(BeZero())
+
+			By("changing the value in the provider")
+			fakeProvider.WithGetSecret([]byte("NEW VALUE"), nil)
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				return createdES.Status.SecretValueUpdates == 1
+			}, timeout, interval).Should(BeTrue())
+			Eventually(valueUpdatedEvents, timeout, interval).Should(Equal(1))
+			Consistently(func() int64 {
+				Expect(k8sClient.Get(ctx, esLookupKey, createdES)).Should(Succeed())
+				return createdES.Status.SecretValueUpdates
+			}, time.Second*3, interval).Should(Equal(int64(1)))
+		})
+
+		It("should keep the last synced data when a refresh fails", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+
+			fakeProvider.WithGetSecret(nil, fmt.Errorf("some api err"))
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			Eventually(func() bool {
+				createdES := &esv1alpha1.ExternalSecret{}
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonSecretSyncedError
+			}, timeout, interval).Should(BeTrue())
+
+			// the secret is not cleared by the failed refreshes
+			Consistently(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, time.Second*3, interval).Should(BeTrue())
+		})
+
+		It("should keep the secret when a refresh fails with the Strict policy", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval:      &metav1.Duration{Duration: time.Second},
+					RefreshFailurePolicy: esv1alpha1.RefreshFailurePolicyStrict,
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+
+			fakeProvider.WithGetSecret(nil, fmt.Errorf("some api err"))
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			Eventually(func() bool {
+				createdES := &esv1alpha1.ExternalSecret{}
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonSecretSyncedError
+			}, timeout, interval).Should(BeTrue())
+
+			Consistently(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, time.Second*3, interval).Should(BeTrue())
+		})
+
+		It("should coalesce secret updates within the minimum write interval", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			const minWriteInterval = time.Second * 8
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval:  &metav1.Duration{Duration: time.Second},
+					MinWriteInterval: &metav1.Duration{Duration: minWriteInterval},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+			resourceVersion := syncedSecret.ResourceVersion
+
+			By("flapping the provider value")
+			for _, val := range []string{"flap1", "flap2", "flap3"} {
+				fakeProvider.WithGetSecret([]byte(val), nil)
+				time.Sleep(time.Second)
+			}
+
+			Consistently(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return syncedSecret.ResourceVersion == resourceVersion && string(syncedSecret.Data[targetProp]) == secretVal
+			}, time.Second*2, interval).Should(BeTrue())
+
+			By("writing the latest value once the window has passed")
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == "flap3"
+			}, timeout, interval).Should(BeTrue())
+			Expect(syncedSecret.ResourceVersion).NotTo(Equal(resourceVersion))
+			resourceVersion = syncedSecret.ResourceVersion
+
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Consistently(func() bool {
+				if err := k8sClient.Get(ctx, secretLookupKey, syncedSecret); err != nil {
+					return false
+				}
+				if err := k8sClient.Get(ctx, esLookupKey, createdES); err != nil {
+					return false
+				}
+				return syncedSecret.ResourceVersion == resourceVersion && createdES.Status.SecretValueUpdates == 1
+			}, time.Second*3, interval).Should(BeTrue())
+		})
+
+		It("should fetch secrets using dataFrom", func() {
+			ctx := context.Background()
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"foo": []byte("bar"),
+				"baz": []byte("bang"),
+			}, nil)
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				x := syncedSecret.Data["foo"]
+				y := syncedSecret.Data["baz"]
+				return string(x) == "bar" && string(y) == "bang"
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should omit excluded keys when using dataFrom", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							Exclude: []string{"foo", "regexp:admin_.*"},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"foo":            []byte("bar"),
+				"foobar":         []byte("bar"),
+				"baz":            []byte("bang"),
+				"admin_user":     []byte("root"),
+				"admin_password": []byte("secret"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data["foobar"]) == "bar" && string(syncedSecret.Data["baz"]) == "bang"
+			}, timeout, interval).Should(BeTrue())
+			Expect(syncedSecret.Data).To(HaveLen(2))
+		})
+
+		It("should create an empty secret when every dataFrom key is excluded", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							Exclude: []string{"regexp:.*"},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"foo": []byte("bar"),
+				"baz": []byte("bang"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(BeEmpty())
+		})
+
+		It("should only write included keys when using dataFrom", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							Include: []string{"regexp:db_.*"},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"db_user":     []byte("admin"),
+				"db_password": []byte("secret"),
+				"db_host":     []byte("localhost"),
+				"api_token":   []byte("token"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"db_user":     []byte("admin"),
+				"db_password": []byte("secret"),
+				"db_host":     []byte("localhost"),
+			}))
+		})
+
+		It("should apply exclude after include when using dataFrom", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							Include: []string{"regexp:db_.*", "api_token"},
+							Exclude: []string{"db_password"},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"db_user":     []byte("admin"),
+				"db_password": []byte("secret"),
+				"db_host":     []byte("localhost"),
+				"api_token":   []byte("token"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"db_user":   []byte("admin"),
+				"db_host":   []byte("localhost"),
+				"api_token": []byte("token"),
+			}))
+		})
+
+		It("should create an empty secret when no dataFrom key is included", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							Include: []string{"db"},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"db_user":     []byte("admin"),
+				"db_password": []byte("secret"),
+				"db_host":     []byte("localhost"),
+				"api_token":   []byte("token"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(BeEmpty())
+		})
+
+		It("should not fetch a pinned version again", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key:     "barz",
+								Version: "1",
+							},
+						},
+					},
+				},
+			}
+
+			var fetches int32
+			fakeProvider.GetSecretFn = func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				atomic.AddInt32(&fetches, 1)
+				return []byte(secretVal), nil
+			}
+			fakeProvider.WithPinnedVersion(func(ref esv1alpha1.ExternalSecretDataRemoteRef) bool {
+				return ref.Version != ""
+			})
+			defer fakeProvider.WithPinnedVersion(nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+
+			Eventually(func() bool {
+				Expect(syncCallsTotal.WithLabelValues(ExternalSecretName, ExternalSecretNamespace).Write(&metric)).To(Succeed())
+				return metric.GetCounter().GetValue() >= 2.0
+			}, timeout, interval).Should(BeTrue())
+			Expect(atomic.LoadInt32(&fetches)).To(BeNumerically("==", 1))
+		})
+
+		It("should not fetch a pinned, decrypted version again", func() {
+			ctx := context.Background()
+			// encrypted with age for the identity below
+			const encrypted = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSAxdjBmT3kxeC9XdFdSZy9O
+aDNiTERJOTU4VlNQZ3F6cUNRazVtSmRBakNFCmtVM29Lck9MRHk2MWhDd2UrMkxT
+V3IyazZ6bmNScDVMbVFycFNqd0lsVm8KLS0tIHZhUERVQUtHd2V1WlhGZlpYWnNP
+bUdQdHdHcG1OQmlFTSt6RGxBRTNIM0kKN/hsuQeHOMNw8H6NO1g7raWqycqeFOLM
+FBVcNCfB2PHu1NesQAUbFaURsm/8QEEu8cJwqleGBvXulsc5RFuY9Rg=
+-----END AGE ENCRYPTED FILE-----
+`
+			identitySecret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "age-key",
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string][]byte{
+					"keys.txt": []byte("AGE-SECRET-KEY-1403GTPWGJJ0DS4QGWZGNUHRD2TMYHT96CL4JVQTEZDSTF9A66RNS7SJNH6\n"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, identitySecret)).Should(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "dsn",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key:     "encrypted-dsn",
+								Version: "1",
+								Decrypt: &esv1alpha1.RemoteRefDecryption{
+									Age: &esv1alpha1.AgeDecryption{
+										IdentitySecretRef: &esmeta.SecretKeySelector{
+											Name: "age-key",
+											Key:  "keys.txt",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			var fetches int32
+			fakeProvider.GetSecretFn = func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				atomic.AddInt32(&fetches, 1)
+				return []byte(encrypted), nil
+			}
+			fakeProvider.WithPinnedVersion(func(ref esv1alpha1.ExternalSecretDataRemoteRef) bool {
+				return ref.Version != ""
+			})
+			defer fakeProvider.WithPinnedVersion(nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data["dsn"]) == "postgres://app:s3cr3t@db:5432/app"
+			}, timeout, interval).Should(BeTrue())
+
+			Eventually(func() bool {
+				Expect(syncCallsTotal.WithLabelValues(ExternalSecretName, ExternalSecretNamespace).Write(&metric)).To(Succeed())
+				return metric.GetCounter().GetValue() >= 2.0
+			}, timeout, interval).Should(BeTrue())
+			Expect(atomic.LoadInt32(&fetches)).To(BeNumerically("==", 1))
+		})
+
+		It("should not fetch again on status updates but on spec changes", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			var fetches int32
+			fakeProvider.GetSecretFn = func(_ context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				atomic.AddInt32(&fetches, 1)
+				return []byte(ref.Key), nil
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			// the creation of the Secret is reconciled as well, wait until no more fetches happen
+			var synced int32
+			Eventually(func() bool {
+				n := atomic.LoadInt32(&fetches)
+				settled := n > 0 && n == synced
+				synced = n
+				return settled
+			}, timeout, time.Second).Should(BeTrue())
+
+			// a status update does not trigger a fetch
+			Expect(k8sClient.Get(ctx, esLookupKey, createdES)).Should(Succeed())
+			createdES.Status.RefreshTime = metav1.NewTime(createdES.Status.RefreshTime.Add(-time.Minute))
+			Expect(k8sClient.Status().Update(ctx, createdES)).Should(Succeed())
+			Consistently(func() int32 {
+				return atomic.LoadInt32(&fetches)
+			}, time.Second*3, interval).Should(Equal(synced))
+
+			// a spec change does
+			Expect(k8sClient.Get(ctx, esLookupKey, createdES)).Should(Succeed())
+			createdES.Spec.Data[0].RemoteRef.Key = "other"
+			Expect(k8sClient.Update(ctx, createdES)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == "other"
+			}, timeout, interval).Should(BeTrue())
+			Expect(atomic.LoadInt32(&fetches)).To(BeNumerically(">", synced))
+		})
+
+		It("should sync an ExternalSecret with syncPolicy Once only once", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					SyncPolicy:      esv1alpha1.SyncPolicyOnce,
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			var fetches int32
+			fakeProvider.GetSecretFn = func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				atomic.AddInt32(&fetches, 1)
+				return []byte(secretVal), nil
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				return createdES.Status.SyncCompletedTime != nil
+			}, timeout, interval).Should(BeTrue())
+			Expect(createdES.Status.LastWriteTime.IsZero()).To(BeFalse())
+
+			// changes of the secret are kept and nothing is fetched again
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return err
+				}
+				syncedSecret.Data[targetProp] = []byte("managed manually")
+				return k8sClient.Update(ctx, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Consistently(func() string {
+				Expect(k8sClient.Get(ctx, secretLookupKey, syncedSecret)).To(Succeed())
+				return string(syncedSecret.Data[targetProp])
+			}, time.Second*3, interval).Should(Equal("managed manually"))
+			Expect(atomic.LoadInt32(&fetches)).To(BeNumerically("==", 1))
+
+			// a new value of the force-sync annotation syncs it again
+			Eventually(func() error {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return err
+				}
+				createdES.Annotations = map[string]string{esv1alpha1.AnnotationForceSync: "1"}
+				return k8sClient.Update(ctx, createdES)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, secretLookupKey, syncedSecret)).To(Succeed())
+				return string(syncedSecret.Data[targetProp])
+			}, timeout, interval).Should(Equal(secretVal))
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, esLookupKey, createdES)).To(Succeed())
+				return createdES.Status.SyncCompletedForceSync
+			}, timeout, interval).Should(Equal("1"))
+			Consistently(func() int32 {
+				return atomic.LoadInt32(&fetches)
+			}, time.Second*2, interval).Should(BeNumerically("==", 2))
+		})
+
+		It("should not fetch or write while paused and sync once resumed", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+					Annotations: map[string]string{
+						esv1alpha1.AnnotationDisabled: "true",
+					},
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			var fetches int32
+			fakeProvider.GetSecretFn = func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				atomic.AddInt32(&fetches, 1)
+				return []byte(secretVal), nil
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretPaused)
+				return cond != nil && cond.Status == v1.ConditionTrue && cond.Reason == esv1alpha1.ConditionReasonDisabled
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Consistently(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, secretLookupKey, syncedSecret))
+			}, time.Second*3, interval).Should(BeTrue())
+			Expect(atomic.LoadInt32(&fetches)).To(BeNumerically("==", 0))
+
+			Eventually(func() error {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return err
+				}
+				delete(createdES.Annotations, esv1alpha1.AnnotationDisabled)
+				return k8sClient.Update(ctx, createdES)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretPaused)
+				return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonResumed
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should not write the secret when the call budget is exceeded with the Strict policy", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					CallBudget: &esv1alpha1.CallBudget{
+						MaxCalls: 2,
+						Policy:   esv1alpha1.CallBudgetPolicyStrict,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "first",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "first",
+							},
+						},
+						{
+							SecretKey: "second",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "second",
+							},
+						},
+						{
+							SecretKey: "third",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "third",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.GetSecretFn = func(_ context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				return []byte(ref.Key), nil
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonCallBudgetExceeded
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Consistently(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{}))
+			}, time.Second*3, interval).Should(BeTrue())
+		})
+
+		It("should write the resolved keys when the call budget is exceeded with the Lenient policy", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					CallBudget: &esv1alpha1.CallBudget{
+						MaxCalls: 2,
+						Policy:   esv1alpha1.CallBudgetPolicyLenient,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "first",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "first",
+							},
+						},
+						{
+							SecretKey: "second",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "second",
+							},
+						},
+						{
+							SecretKey: "third",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "third",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.GetSecretFn = func(_ context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				return []byte(ref.Key), nil
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionTrue && cond.Reason == esv1alpha1.ConditionReasonCallBudgetExceeded
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, secretLookupKey, syncedSecret)).To(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"first":  []byte("first"),
+				"second": []byte("second"),
+			}))
+		})
+
+		It("should store exploded keys and the json blob from one fetch", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							JSONKey: "config.json",
+						},
+					},
+				},
+			}
+
+			var fetches int32
+			fakeProvider.GetSecretMapFn = func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+				atomic.AddInt32(&fetches, 1)
+				return map[string][]byte{
+					"foo": []byte("bar"),
+					"baz": []byte("bang"),
+				}, nil
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"foo":         []byte("bar"),
+				"baz":         []byte("bang"),
+				"config.json": []byte(`{"baz":"bang","foo":"bar"}`),
+			}))
+			Expect(atomic.LoadInt32(&fetches)).To(BeNumerically("==", 1))
+		})
+
+		It("should set an error condition when the json key collides with a fetched key", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							JSONKey: "foo",
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"foo": []byte("bar"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretSyncedError {
+					return false
+				}
+				return strings.Contains(cond.Message, "collides")
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should transform camelCase keys to upper snake case", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							KeyTransform: []esv1alpha1.KeyTransform{
+								esv1alpha1.KeyTransformCamelToSnake,
+								esv1alpha1.KeyTransformToUpper,
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"dbPassword":    []byte("secret"),
+				"HTTPServerURL": []byte("https://example.com"),
+				"apiKey2":       []byte("token"),
+				"already_snake": []byte("snake"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"DB_PASSWORD":     []byte("secret"),
+				"HTTP_SERVER_URL": []byte("https://example.com"),
+				"API_KEY2":        []byte("token"),
+				"ALREADY_SNAKE":   []byte("snake"),
+			}))
+		})
+
+		It("should set an error condition when transformed keys collide", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							KeyTransform: []esv1alpha1.KeyTransform{
+								esv1alpha1.KeyTransformToUpper,
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"dbPassword": []byte("secret"),
+				"DBPassword": []byte("other"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretSyncedError {
+					return false
+				}
+				return strings.Contains(cond.Message, `keys "DBPassword" and "dbPassword" both transform to "DBPASSWORD"`)
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should strip the prefix from keys and keep keys without it", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							StripPrefix: "APP_PROD_",
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"APP_PROD_DB_HOST":  []byte("db.example.com"),
+				"APP_PROD_DB_USER":  []byte("admin"),
+				"APP_STAGE_DB_HOST": []byte("stage.example.com"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"DB_HOST":           []byte("db.example.com"),
+				"DB_USER":           []byte("admin"),
+				"APP_STAGE_DB_HOST": []byte("stage.example.com"),
+			}))
+		})
+
+		It("should drop keys without the prefix when dropUnprefixedKeys is set", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+							StripPrefix:        "APP_PROD_",
+							DropUnprefixedKeys: true,
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretMap(map[string][]byte{
+				"APP_PROD_DB_HOST":  []byte("db.example.com"),
+				"APP_PROD_DB_USER":  []byte("admin"),
+				"APP_STAGE_DB_HOST": []byte("stage.example.com"),
+			}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"DB_HOST": []byte("db.example.com"),
+				"DB_USER": []byte("admin"),
+			}))
+		})
+
+		It("should write the digest of every value when digestKeys is set", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name:       ExternalSecretTargetSecretName,
+						DigestKeys: true,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+			sum := sha256.Sum256([]byte(secretVal))
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				targetProp:             []byte(secretVal),
+				targetProp + ".sha256": []byte(hex.EncodeToString(sum[:])),
+			}))
+		})
+
+		It("should not write digests to typed secrets", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name:       ExternalSecretTargetSecretName,
+						DigestKeys: true,
+						Template: &esv1alpha1.ExternalSecretTemplate{
+							Type: v1.SecretTypeTLS,
+						},
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: v1.TLSCertKey,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "cert",
+							},
+						},
+						{
+							SecretKey: v1.TLSPrivateKeyKey,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "key",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{
+				"cert": []byte("certificate"),
+				"key":  []byte("private key"),
+			})
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				v1.TLSCertKey:       []byte("certificate"),
+				v1.TLSPrivateKeyKey: []byte("private key"),
+			}))
+		})
+
+		It("should write all values as shell variables to shellEnvKey", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name:        ExternalSecretTargetSecretName,
+						ShellEnvKey: "env.sh",
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "DB_PASSWORD",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "password",
+							},
+						},
+						{
+							SecretKey: "DB_USER",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "user",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{
+				"password": []byte("it's $ecret"),
+				"user":     []byte("app user"),
+			})
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"DB_PASSWORD": []byte("it's $ecret"),
+				"DB_USER":     []byte("app user"),
+				"env.sh":      []byte("DB_PASSWORD='it'\\''s $ecret'\nDB_USER='app user'\n"),
+			}))
+		})
+
+		It("should not sync if a key is no valid shell variable name", func() {
+			ctx := context.Background()
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name:        ExternalSecretTargetSecretName,
+						ShellEnvKey: "env.sh",
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "db-password",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "password",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte("secret"), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esKey := types.NamespacedName{Name: ExternalSecretName, Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, esKey, createdES); err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse
+			}, timeout, interval).Should(BeTrue())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{}))).To(BeTrue())
+		})
+
+		It("should not write digests by default", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+			Expect(syncedSecret.Data).To(HaveLen(1))
+			Expect(syncedSecret.Data).ToNot(HaveKey(targetProp + ".sha256"))
+		})
+
+		It("should recreate a secret that was made immutable outside of the controller", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+
+			oldUID := syncedSecret.UID
+			immutable := true
+			syncedSecret.Immutable = &immutable
+			Expect(k8sClient.Update(ctx, syncedSecret)).Should(Succeed())
+
+			Eventually(func() bool {
+				secret := &v1.Secret{}
+				err := k8sClient.Get(ctx, secretLookupKey, secret)
+				if err != nil {
+					return false
+				}
+				return secret.UID != oldUID && secret.Immutable == nil && string(secret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+
+			Eventually(func() bool {
+				events := &v1.EventList{}
+				err := k8sClient.List(ctx, events, client.InNamespace(ExternalSecretNamespace))
+				if err != nil {
+					return false
+				}
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == ExternalSecretName && event.Reason == "DriftCorrected" {
+						return true
+					}
+				}
+				return false
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should recreate a mutable secret when target.immutable is set", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+			Expect(syncedSecret.Immutable).To(BeNil())
+
+			oldUID := syncedSecret.UID
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Expect(k8sClient.Get(ctx, esLookupKey, createdES)).Should(Succeed())
+			createdES.Spec.Target.Immutable = true
+			Expect(k8sClient.Update(ctx, createdES)).Should(Succeed())
+
+			Eventually(func() bool {
+				secret := &v1.Secret{}
+				err := k8sClient.Get(ctx, secretLookupKey, secret)
+				if err != nil {
+					return false
+				}
+				return secret.UID != oldUID && secret.Immutable != nil && *secret.Immutable && string(secret.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should recreate an immutable secret and its copies when the data changes", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			const newSecretVal = "someNewValue"
+			err := k8sClient.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: allowedTargetNamespace}})
+			Expect(err == nil || apierrors.IsAlreadyExists(err)).To(BeTrue())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					RefreshInterval: &metav1.Duration{Duration: time.Second},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name:       ExternalSecretTargetSecretName,
+						Immutable:  true,
+						Namespaces: []string{allowedTargetNamespace},
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			lookupKeys := []types.NamespacedName{
+				{Name: ExternalSecretTargetSecretName, Namespace: ExternalSecretNamespace},
+				{Name: ExternalSecretTargetSecretName, Namespace: allowedTargetNamespace},
+			}
+			oldUIDs := make(map[types.NamespacedName]types.UID)
+			for _, key := range lookupKeys {
+				secret := &v1.Secret{}
+				Eventually(func() bool {
+					err := k8sClient.Get(ctx, key, secret)
+					if err != nil {
+						return false
+					}
+					return string(secret.Data[targetProp]) == secretVal
+				}, timeout, interval).Should(BeTrue())
+				Expect(secret.Immutable).ToNot(BeNil())
+				Expect(*secret.Immutable).To(BeTrue())
+				oldUIDs[key] = secret.UID
+			}
+
+			fakeProvider.WithGetSecret([]byte(newSecretVal), nil)
+			for _, key := range lookupKeys {
+				Eventually(func() bool {
+					secret := &v1.Secret{}
+					err := k8sClient.Get(ctx, key, secret)
+					if err != nil {
+						return false
+					}
+					return secret.UID != oldUIDs[key] && secret.Immutable != nil && *secret.Immutable && string(secret.Data[targetProp]) == newSecretVal
+				}, timeout, interval).Should(BeTrue())
+			}
+		})
+
+		It("should assemble chunked secrets in the declared order", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Assemble: []esv1alpha1.ExternalSecretAssembly{
+							{
+								SecretKey: targetProp,
+								RemoteRefs: []esv1alpha1.ExternalSecretDataRemoteRef{
+									{Key: "part1"},
+									{Key: "part2"},
+									{Key: "part3"},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{
+				"part3": []byte("baz"),
+				"part1": []byte("foo"),
+				"part2": []byte("bar"),
+			})
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == "foobarbaz"
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should set an error condition when a chunk is missing", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Assemble: []esv1alpha1.ExternalSecretAssembly{
+							{
+								SecretKey: targetProp,
+								RemoteRefs: []esv1alpha1.ExternalSecretDataRemoteRef{
+									{Key: "part1"},
+									{Key: "part2"},
+									{Key: "part3"},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{
+				"part1": []byte("foo"),
+				"part3": []byte("baz"),
+			})
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretSyncedError {
+					return false
+				}
+				return strings.Contains(cond.Message, "part2")
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should compose a nested JSON document from multiple properties", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Compose: []esv1alpha1.ExternalSecretComposition{
+							{
+								SecretKey: targetProp,
+								Fields: []esv1alpha1.ExternalSecretCompositionField{
+									{Path: "db.user", RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "legacy", Property: "username"}},
+									{Path: "db.password", RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "legacy", Property: "password"}},
+									{Path: "token", RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "legacy", Property: "apiToken"}},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			properties := map[string]string{
+				"username": "admin",
+				"password": "secret",
+				"apiToken": "abc",
+			}
+			fakeProvider.GetSecretFn = func(_ context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+				return []byte(properties[ref.Property]), nil
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+			Expect(syncedSecret.Data).To(HaveLen(1))
+			Expect(syncedSecret.Data[targetProp]).To(MatchJSON(`{"db":{"user":"admin","password":"secret"},"token":"abc"}`))
+		})
+
+		It("should set an error condition when composed paths collide", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+						Compose: []esv1alpha1.ExternalSecretComposition{
+							{
+								SecretKey: targetProp,
+								Fields: []esv1alpha1.ExternalSecretCompositionField{
+									{Path: "db.user", RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "legacy", Property: "username"}},
+									{Path: "db", RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{Key: "legacy", Property: "host"}},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte("value"), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretSyncedError {
+					return false
+				}
+				return strings.Contains(cond.Message, "collides")
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should sync ExternalSecrets in dependency order", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			const secretVal = "someValue"
+			newES := func(name string, dependsOn ...string) *esv1alpha1.ExternalSecret {
+				return &esv1alpha1.ExternalSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: ExternalSecretNamespace,
+					},
+					Spec: esv1alpha1.ExternalSecretSpec{
+						SecretStoreRef: esv1alpha1.SecretStoreRef{
+							Name: ExternalSecretStore,
+						},
+						Target: esv1alpha1.ExternalSecretTarget{
+							Name: name,
+						},
+						Data: []esv1alpha1.ExternalSecretData{
+							{
+								SecretKey: targetProp,
+								RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+									Key: "barz",
+								},
+							},
+						},
+						DependsOn: dependsOn,
+					},
+				}
+			}
+			readyReason := func(name string) string {
+				createdES := &esv1alpha1.ExternalSecret{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ExternalSecretNamespace}, createdES)
+				if err != nil {
+					return ""
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil {
+					return ""
+				}
+				return cond.Reason
+			}
+
+			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			// app -> credentials -> bootstrap
+			Expect(k8sClient.Create(ctx, newES("app", "credentials"))).Should(Succeed())
+			Expect(k8sClient.Create(ctx, newES("credentials", "bootstrap"))).Should(Succeed())
+			Eventually(func() string {
+				return readyReason("app")
+			}, timeout, interval).Should(Equal(esv1alpha1.ConditionReasonWaitingForDependency))
+			Eventually(func() string {
+				return readyReason("credentials")
+			}, timeout, interval).Should(Equal(esv1alpha1.ConditionReasonWaitingForDependency))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "app", Namespace: ExternalSecretNamespace}, &v1.Secret{})).ShouldNot(Succeed())
+
+			Expect(k8sClient.Create(ctx, newES("bootstrap"))).Should(Succeed())
+			for _, name := range []string{"bootstrap", "credentials", "app"} {
+				Eventually(func() string {
+					return readyReason(name)
+				}, timeout, interval).Should(Equal(esv1alpha1.ConditionReasonSecretSynced))
+				syncedSecret := &v1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ExternalSecretNamespace}, syncedSecret)).Should(Succeed())
+				Expect(string(syncedSecret.Data[targetProp])).To(Equal(secretVal))
+			}
+		})
+
+		It("should reject dependency cycles", func() {
+			ctx := context.Background()
+			newES := func(name string, dependsOn ...string) *esv1alpha1.ExternalSecret {
+				return &esv1alpha1.ExternalSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: ExternalSecretNamespace,
+					},
+					Spec: esv1alpha1.ExternalSecretSpec{
+						SecretStoreRef: esv1alpha1.SecretStoreRef{
+							Name: ExternalSecretStore,
+						},
+						Target: esv1alpha1.ExternalSecretTarget{
+							Name: name,
+						},
+						DependsOn: dependsOn,
+					},
+				}
+			}
+
+			Expect(k8sClient.Create(ctx, newES("first", "second"))).Should(Succeed())
+			Expect(k8sClient.Create(ctx, newES("second", "first"))).Should(Succeed())
+			for _, name := range []string{"first", "second"} {
+				esLookupKey := types.NamespacedName{
+					Name:      name,
+					Namespace: ExternalSecretNamespace}
+				createdES := &esv1alpha1.ExternalSecret{}
+				Eventually(func() bool {
+					err := k8sClient.Get(ctx, esLookupKey, createdES)
+					if err != nil {
+						return false
+					}
+					cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+					return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonDependencyCycle
+				}, timeout, interval).Should(BeTrue())
+			}
+		})
+
+		It("should sync a secret just under the size limit", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			// the size of a secret is the sum of its keys and values
+			fakeProvider.WithGetSecret(make([]byte, v1.MaxSecretSize-len(targetProp)-1), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+			}, timeout, interval).Should(Succeed())
+			Expect(syncedSecret.Data[targetProp]).To(HaveLen(v1.MaxSecretSize - len(targetProp) - 1))
+		})
+
+		It("should set a SecretTooLarge condition when the secret exceeds the size limit", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret(make([]byte, v1.MaxSecretSize-len(targetProp)+1), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretTooLarge {
+					return false
+				}
+				return strings.Contains(cond.Message, fmt.Sprintf("%d bytes", v1.MaxSecretSize+1))
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should set an error condition when provider errors", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key:      "barz",
+								Property: "bang",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret(nil, fmt.Errorf("artificial testing error"))
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				// condition must be false
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretSyncedError {
+					return false
+				}
+				return true
+			}, timeout, interval).Should(BeTrue())
+
+			Eventually(func() bool {
+				Expect(syncCallsError.WithLabelValues(ExternalSecretName, ExternalSecretNamespace).Write(&metric)).To(Succeed())
+				return metric.GetCounter().GetValue() >= 2.0
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(externalSecretConditionShouldBe(ExternalSecretName, ExternalSecretNamespace, esv1alpha1.ExternalSecretReady, v1.ConditionFalse, 1.0)).To(BeTrue())
+			Expect(externalSecretConditionShouldBe(ExternalSecretName, ExternalSecretNamespace, esv1alpha1.ExternalSecretReady, v1.ConditionTrue, 0.0)).To(BeTrue())
+		})
+
+		It("should set a DisallowedKMSKey condition when the provider refuses the KMS key", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret(nil, fmt.Errorf("%w: secret barz is encrypted with alias/other", provider.ErrDisallowedKMSKey))
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
+				Namespace: ExternalSecretNamespace}
+			createdES := &esv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
+				if err != nil {
+					return false
+				}
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonDisallowedKMSKey
+			}, timeout, interval).Should(BeTrue())
+
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should assemble the secret from the stores of the entries", func() {
+			ctx := context.Background()
+			const vaultStore = "test-vault-store"
+			Expect(k8sClient.Create(ctx, &esv1alpha1.SecretStore{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      vaultStore,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						Vault: &esv1alpha1.VaultProvider{
+							Server: "https://vault.example.com",
+							Path:   "secret",
+							Auth: esv1alpha1.VaultAuth{
+								TokenSecretRef: &esmeta.SecretKeySelector{Name: "vault-token", Key: "token"},
+							},
+						},
+					},
+				},
+			})).To(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: ExternalSecretStore,
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: "username",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "db-user",
+							},
+						},
+						{
+							SecretKey: "password",
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "db-password",
+							},
+							SecretStoreRef: &esv1alpha1.SecretStoreRef{
+								Name: vaultStore,
+							},
+						},
+					},
+					DataFrom: []esv1alpha1.ExternalSecretDataFromRemoteRef{
+						{
+							ExternalSecretDataRemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "db-config",
+							},
+							SecretStoreRef: &esv1alpha1.SecretStoreRef{
+								Name: vaultStore,
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecretByKey(map[string][]byte{"db-user": []byte("aws-user")})
+			vaultProvider.WithGetSecretByKey(map[string][]byte{"db-password": []byte("vault-password")})
+			vaultProvider.WithGetSecretMap(map[string][]byte{"host": []byte("vault-host")}, nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+			Expect(syncedSecret.Data).To(Equal(map[string][]byte{
+				"username": []byte("aws-user"),
+				"password": []byte("vault-password"),
+				"host":     []byte("vault-host"),
+			}))
+		})
+
+		It("should not sync if the store of an entry does not exist", func() {
+			ctx := context.Background()
 			es := &esv1alpha1.ExternalSecret{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      ExternalSecretName,
 					Namespace: ExternalSecretNamespace,
 				},
 				Spec: esv1alpha1.ExternalSecretSpec{
-					RefreshInterval: &metav1.Duration{Duration: time.Second},
 					SecretStoreRef: esv1alpha1.SecretStoreRef{
 						Name: ExternalSecretStore,
 					},
@@ -321,45 +3382,99 @@ var _ = Describe("ExternalSecret controller", func() {
 					},
 					Data: []esv1alpha1.ExternalSecretData{
 						{
-							SecretKey: targetProp,
+							SecretKey: "password",
 							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
-								Key: "barz",
+								Key: "db-password",
+							},
+							SecretStoreRef: &esv1alpha1.SecretStoreRef{
+								Name: "missing-store",
 							},
 						},
 					},
 				},
 			}
 
-			fakeProvider.WithGetSecret([]byte(secretVal), nil)
+			fakeProvider.WithGetSecret([]byte("someValue"), nil)
 			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
-			secretLookupKey := types.NamespacedName{
-				Name:      ExternalSecretTargetSecretName,
+			esLookupKey := types.NamespacedName{
+				Name:      ExternalSecretName,
 				Namespace: ExternalSecretNamespace}
-			syncedSecret := &v1.Secret{}
+			createdES := &esv1alpha1.ExternalSecret{}
 			Eventually(func() bool {
-				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				err := k8sClient.Get(ctx, esLookupKey, createdES)
 				if err != nil {
 					return false
 				}
-				v := syncedSecret.Data[targetProp]
-				return string(v) == secretVal
+				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+				return cond != nil && cond.Status == v1.ConditionFalse && strings.Contains(cond.Message, `could not get SecretStore "missing-store"`)
 			}, timeout, interval).Should(BeTrue())
 
-			newValue := "NEW VALUE"
-			fakeProvider.WithGetSecret([]byte(newValue), nil)
-			Eventually(func() bool {
-				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
-				if err != nil {
-					return false
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			Expect(k8sClient.Get(ctx, secretLookupKey, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should set the condition reason of provider errors", func() {
+			ctx := context.Background()
+			for i, tc := range []struct {
+				err    error
+				reason string
+			}{
+				{err: fmt.Errorf("%w: not authorized to read barz", provider.ErrAccessDenied), reason: esv1alpha1.ConditionReasonAccessDenied},
+				{err: fmt.Errorf("%w: barz", provider.ErrSecretNotFound), reason: esv1alpha1.ConditionReasonSecretNotFound},
+				{err: fmt.Errorf("%w: KMS key of barz is disabled", provider.ErrDecryptionFailed), reason: esv1alpha1.ConditionReasonProviderDecryptionFailed},
+				{err: fmt.Errorf("%w: rate exceeded", provider.ErrThrottled), reason: esv1alpha1.ConditionReasonThrottled},
+				{err: fmt.Errorf("InternalServiceError: something went wrong"), reason: esv1alpha1.ConditionReasonSecretSyncedError},
+			} {
+				es := &esv1alpha1.ExternalSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("%s-%d", ExternalSecretName, i),
+						Namespace: ExternalSecretNamespace,
+					},
+					Spec: esv1alpha1.ExternalSecretSpec{
+						SecretStoreRef: esv1alpha1.SecretStoreRef{
+							Name: ExternalSecretStore,
+						},
+						Target: esv1alpha1.ExternalSecretTarget{
+							Name: fmt.Sprintf("%s-%d", ExternalSecretTargetSecretName, i),
+						},
+						Data: []esv1alpha1.ExternalSecretData{
+							{
+								SecretKey: "targetProperty",
+								RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+									Key: "barz",
+								},
+							},
+						},
+					},
 				}
-				v := syncedSecret.Data[targetProp]
-				return string(v) == newValue
-			}, timeout, interval).Should(BeTrue())
+				fakeProvider.WithGetSecret(nil, tc.err)
+				Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+				esLookupKey := types.NamespacedName{
+					Name:      es.Name,
+					Namespace: ExternalSecretNamespace}
+				createdES := &esv1alpha1.ExternalSecret{}
+				Eventually(func() string {
+					err := k8sClient.Get(ctx, esLookupKey, createdES)
+					if err != nil {
+						return ""
+					}
+					cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
+					if cond == nil || cond.Status != v1.ConditionFalse {
+						return ""
+					}
+					return cond.Reason
+				}, timeout, interval).Should(Equal(tc.reason))
+			}
 		})
 
-		It("should fetch secrets using dataFrom", func() {
+		It("should copy the secret to an allowed namespace and delete the copy with the ExternalSecret", func() {
 			ctx := context.Background()
+			const targetProp = "targetProperty"
 			const secretVal = "someValue"
+			err := k8sClient.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: allowedTargetNamespace}})
+			Expect(err == nil || apierrors.IsAlreadyExists(err)).To(BeTrue())
 			es := &esv1alpha1.ExternalSecret{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      ExternalSecretName,
@@ -370,38 +3485,43 @@ var _ = Describe("ExternalSecret controller", func() {
 						Name: ExternalSecretStore,
 					},
 					Target: esv1alpha1.ExternalSecretTarget{
-						Name: ExternalSecretTargetSecretName,
+						Name:       ExternalSecretTargetSecretName,
+						Namespaces: []string{allowedTargetNamespace},
 					},
-					DataFrom: []esv1alpha1.ExternalSecretDataRemoteRef{
+					Data: []esv1alpha1.ExternalSecretData{
 						{
-							Key: "barz",
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
 						},
 					},
 				},
 			}
 
-			fakeProvider.WithGetSecretMap(map[string][]byte{
-				"foo": []byte("bar"),
-				"baz": []byte("bang"),
-			}, nil)
 			fakeProvider.WithGetSecret([]byte(secretVal), nil)
 			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
-			secretLookupKey := types.NamespacedName{
+			replicaLookupKey := types.NamespacedName{
 				Name:      ExternalSecretTargetSecretName,
-				Namespace: ExternalSecretNamespace}
-			syncedSecret := &v1.Secret{}
+				Namespace: allowedTargetNamespace}
+			replica := &v1.Secret{}
 			Eventually(func() bool {
-				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				err := k8sClient.Get(ctx, replicaLookupKey, replica)
 				if err != nil {
 					return false
 				}
-				x := syncedSecret.Data["foo"]
-				y := syncedSecret.Data["baz"]
-				return string(x) == "bar" && string(y) == "bang"
+				return string(replica.Data[targetProp]) == secretVal
+			}, timeout, interval).Should(BeTrue())
+			Expect(replica.Labels).To(HaveKeyWithValue("external-secrets.io/owner-uid", string(es.UID)))
+
+			Expect(k8sClient.Delete(ctx, es)).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, replicaLookupKey, &v1.Secret{})
+				return apierrors.IsNotFound(err)
 			}, timeout, interval).Should(BeTrue())
 		})
 
-		It("should set an error condition when provider errors", func() {
+		It("should set a NamespaceNotAllowed condition when a target namespace is not allowed", func() {
 			ctx := context.Background()
 			const targetProp = "targetProperty"
 			es := &esv1alpha1.ExternalSecret{
@@ -414,21 +3534,21 @@ var _ = Describe("ExternalSecret controller", func() {
 						Name: ExternalSecretStore,
 					},
 					Target: esv1alpha1.ExternalSecretTarget{
-						Name: ExternalSecretTargetSecretName,
+						Name:       ExternalSecretTargetSecretName,
+						Namespaces: []string{"kube-system"},
 					},
 					Data: []esv1alpha1.ExternalSecretData{
 						{
 							SecretKey: targetProp,
 							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
-								Key:      "barz",
-								Property: "bang",
+								Key: "barz",
 							},
 						},
 					},
 				},
 			}
 
-			fakeProvider.WithGetSecret(nil, fmt.Errorf("artificial testing error"))
+			fakeProvider.WithGetSecret([]byte("someValue"), nil)
 			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
 			esLookupKey := types.NamespacedName{
 				Name:      ExternalSecretName,
@@ -439,21 +3559,107 @@ var _ = Describe("ExternalSecret controller", func() {
 				if err != nil {
 					return false
 				}
-				// condition must be false
 				cond := GetExternalSecretCondition(createdES.Status, esv1alpha1.ExternalSecretReady)
-				if cond == nil || cond.Status != v1.ConditionFalse || cond.Reason != esv1alpha1.ConditionReasonSecretSyncedError {
+				return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == esv1alpha1.ConditionReasonNamespaceNotAllowed
+			}, timeout, interval).Should(BeTrue())
+
+			// nothing is written, neither the secret nor a copy
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}, &v1.Secret{})).ShouldNot(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: "kube-system"}, &v1.Secret{})).ShouldNot(Succeed())
+		})
+
+		It("should sync again when a credential Secret of the store is rotated", func() {
+			ctx := context.Background()
+			const targetProp = "targetProperty"
+			credentials := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "aws-credentials",
+					Namespace: ExternalSecretNamespace,
+				},
+				Data: map[string][]byte{
+					"access-key-id":     []byte("old-id"),
+					"secret-access-key": []byte("old-key"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, credentials)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &esv1alpha1.SecretStore{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "store-with-credentials",
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							Service: esv1alpha1.AWSServiceSecretsManager,
+							Auth: &esv1alpha1.AWSAuth{
+								SecretRef: esv1alpha1.AWSAuthSecretRef{
+									AccessKeyID: esmeta.SecretKeySelector{
+										Name: "aws-credentials",
+										Key:  "access-key-id",
+									},
+									SecretAccessKey: esmeta.SecretKeySelector{
+										Name: "aws-credentials",
+										Key:  "secret-access-key",
+									},
+								},
+							},
+						},
+					},
+				},
+			})).To(Succeed())
+			es := &esv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ExternalSecretName,
+					Namespace: ExternalSecretNamespace,
+				},
+				Spec: esv1alpha1.ExternalSecretSpec{
+					RefreshInterval: &metav1.Duration{Duration: time.Hour},
+					SecretStoreRef: esv1alpha1.SecretStoreRef{
+						Name: "store-with-credentials",
+					},
+					Target: esv1alpha1.ExternalSecretTarget{
+						Name: ExternalSecretTargetSecretName,
+					},
+					Data: []esv1alpha1.ExternalSecretData{
+						{
+							SecretKey: targetProp,
+							RemoteRef: esv1alpha1.ExternalSecretDataRemoteRef{
+								Key: "barz",
+							},
+						},
+					},
+				},
+			}
+
+			fakeProvider.WithGetSecret([]byte("someValue"), nil)
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			secretLookupKey := types.NamespacedName{
+				Name:      ExternalSecretTargetSecretName,
+				Namespace: ExternalSecretNamespace}
+			syncedSecret := &v1.Secret{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
 					return false
 				}
-				return true
+				return string(syncedSecret.Data[targetProp]) == "someValue"
 			}, timeout, interval).Should(BeTrue())
 
+			By("rotating the credentials")
+			fakeProvider.WithGetSecret([]byte("NEW VALUE"), nil)
+			credentials.Data["secret-access-key"] = []byte("new-key")
+			Expect(k8sClient.Update(ctx, credentials)).Should(Succeed())
 			Eventually(func() bool {
-				Expect(syncCallsError.WithLabelValues(ExternalSecretName, ExternalSecretNamespace).Write(&metric)).To(Succeed())
-				return metric.GetCounter().GetValue() >= 2.0
+				err := k8sClient.Get(ctx, secretLookupKey, syncedSecret)
+				if err != nil {
+					return false
+				}
+				return string(syncedSecret.Data[targetProp]) == "NEW VALUE"
 			}, timeout, interval).Should(BeTrue())
-
-			Expect(externalSecretConditionShouldBe(ExternalSecretName, ExternalSecretNamespace, esv1alpha1.ExternalSecretReady, v1.ConditionFalse, 1.0)).To(BeTrue())
-			Expect(externalSecretConditionShouldBe(ExternalSecretName, ExternalSecretNamespace, esv1alpha1.ExternalSecretReady, v1.ConditionTrue, 0.0)).To(BeTrue())
 		})
 
 		It("should set an error condition when store does not exist", func() {
@@ -651,6 +3857,132 @@ var _ = Describe("ExternalSecret controller", func() {
 	})
 })
 
+var _ = Describe("requeue jitter", func() {
+	It("should keep the requeue within the jittered range", func() {
+		const interval = time.Hour
+		min := 54 * time.Minute
+		max := 66 * time.Minute
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 100; i++ {
+			d := jitter(interval, 0.1)
+			Expect(d).To(BeNumerically(">=", min))
+			Expect(d).To(BeNumerically("<=", max))
+			seen[d] = true
+		}
+		Expect(len(seen)).To(BeNumerically(">", 1))
+	})
+
+	It("should use the exact interval without jitter", func() {
+		Expect(jitter(time.Hour, 0)).To(Equal(time.Hour))
+	})
+
+	It("should not requeue when the refresh interval is zero", func() {
+		Expect(jitter(0, 0.1)).To(Equal(time.Duration(0)))
+	})
+
+	It("should clamp the fraction below one", func() {
+		for i := 0; i < 100; i++ {
+			Expect(jitter(time.Hour, 5)).To(BeNumerically(">", 0))
+			Expect(jitter(time.Hour, 5)).To(BeNumerically("<", 2*time.Hour))
+		}
+	})
+})
+
+var _ = Describe("refresh interval", func() {
+	var namespace string
+	r := &Reconciler{}
+	clusterStore := &esv1alpha1.ClusterSecretStore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "refresh-interval-cluster-store",
+		},
+		Spec: esv1alpha1.SecretStoreSpec{
+			Provider:        &esv1alpha1.SecretStoreProvider{AWS: &esv1alpha1.AWSProvider{Service: esv1alpha1.AWSServiceSecretsManager}},
+			RefreshInterval: &metav1.Duration{Duration: 2 * time.Minute},
+		},
+	}
+
+	BeforeEach(func() {
+		var err error
+		namespace, err = CreateNamespace("refresh-interval", k8sClient)
+		Expect(err).ToNot(HaveOccurred())
+		r.Client = k8sClient
+		ctx := context.Background()
+		Expect(k8sClient.Create(ctx, &esv1alpha1.SecretStore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "with-interval",
+				Namespace: namespace,
+			},
+			Spec: esv1alpha1.SecretStoreSpec{
+				Provider:        &esv1alpha1.SecretStoreProvider{AWS: &esv1alpha1.AWSProvider{Service: esv1alpha1.AWSServiceSecretsManager}},
+				RefreshInterval: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &esv1alpha1.SecretStore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "without-interval",
+				Namespace: namespace,
+			},
+			Spec: esv1alpha1.SecretStoreSpec{
+				Provider: &esv1alpha1.SecretStoreProvider{AWS: &esv1alpha1.AWSProvider{Service: esv1alpha1.AWSServiceSecretsManager}},
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, clusterStore.DeepCopy())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(context.Background(), &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Delete(context.Background(), clusterStore.DeepCopy())).To(Succeed())
+	})
+
+	externalSecret := func(store esv1alpha1.SecretStoreRef, interval *metav1.Duration) *esv1alpha1.ExternalSecret {
+		return &esv1alpha1.ExternalSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "refresh-interval",
+				Namespace: namespace,
+			},
+			Spec: esv1alpha1.ExternalSecretSpec{
+				SecretStoreRef:  store,
+				RefreshInterval: interval,
+			},
+		}
+	}
+
+	It("should prefer the interval of the ExternalSecret over the store", func() {
+		es := externalSecret(esv1alpha1.SecretStoreRef{Name: "with-interval"}, &metav1.Duration{Duration: 10 * time.Minute})
+		Expect(r.refreshInterval(context.Background(), es)).To(Equal(10 * time.Minute))
+	})
+
+	It("should keep a zero interval of the ExternalSecret", func() {
+		es := externalSecret(esv1alpha1.SecretStoreRef{Name: "with-interval"}, &metav1.Duration{})
+		Expect(r.refreshInterval(context.Background(), es)).To(BeZero())
+	})
+
+	It("should use the interval of the SecretStore", func() {
+		es := externalSecret(esv1alpha1.SecretStoreRef{Name: "with-interval"}, nil)
+		Expect(r.refreshInterval(context.Background(), es)).To(Equal(5 * time.Minute))
+	})
+
+	It("should use the interval of the ClusterSecretStore", func() {
+		es := externalSecret(esv1alpha1.SecretStoreRef{Name: clusterStore.Name, Kind: esv1alpha1.ClusterSecretStoreKind}, nil)
+		Expect(r.refreshInterval(context.Background(), es)).To(Equal(2 * time.Minute))
+	})
+
+	It("should fall back to the controller default", func() {
+		for _, store := range []esv1alpha1.SecretStoreRef{
+			{Name: "without-interval"},
+			{Name: "does-not-exist"},
+			{},
+		} {
+			es := externalSecret(store, nil)
+			Expect(r.refreshInterval(context.Background(), es)).To(Equal(defaultRefreshInterval))
+		}
+	})
+})
+
 // CreateNamespace creates a new namespace in the cluster.
 func CreateNamespace(baseName string, c client.Client) (string, error) {
 	genName := fmt.Sprintf("ctrl-test-%v", baseName)
@@ -687,4 +4019,173 @@ func init() {
 			Service: esv1alpha1.AWSServiceSecretsManager,
 		},
 	})
+	vaultProvider = fake.New()
+	schema.ForceRegister(vaultProvider, &esv1alpha1.SecretStoreProvider{
+		Vault: &esv1alpha1.VaultProvider{},
+	})
+}
+
+func TestAddJSONKey(t *testing.T) {
+	tbl := []struct {
+		test     string
+		data     map[string][]byte
+		jsonKey  string
+		expected string
+		expErr   bool
+	}{
+		{
+			test:     "keys are sorted",
+			data:     map[string][]byte{"user": []byte("admin"), "db_port": []byte("5432"), "db_host": []byte("db.example.com")},
+			jsonKey:  "config.json",
+			expected: `{"db_host":"db.example.com","db_port":"5432","user":"admin"}`,
+		},
+		{
+			test:     "values are escaped",
+			data:     map[string][]byte{"password": []byte("p\"w\\<&>\n")},
+			jsonKey:  "config.json",
+			expected: `{"password":"p\"w\\<&>\n"}`,
+		},
+		{
+			test:     "utf-8 values are not escaped",
+			data:     map[string][]byte{"greeting": []byte("grüße 🔑")},
+			jsonKey:  "config.json",
+			expected: `{"greeting":"grüße 🔑"}`,
+		},
+		{
+			test:    "binary values are rejected",
+			data:    map[string][]byte{"keystore": {0xfe, 0xed, 0xfe, 0xed, 0x00, 0x02}},
+			jsonKey: "config.json",
+			expErr:  true,
+		},
+		{
+			test:     "empty secret",
+			data:     map[string][]byte{},
+			jsonKey:  "config.json",
+			expected: `{}`,
+		},
+		{
+			test:    "json key collides with a key",
+			data:    map[string][]byte{"config.json": []byte("{}")},
+			jsonKey: "config.json",
+			expErr:  true,
+		},
+	}
+
+	for _, row := range tbl {
+		t.Run(row.test, func(t *testing.T) {
+			in := make(map[string][]byte, len(row.data))
+			for k, v := range row.data {
+				in[k] = v
+			}
+			// the serialization must not depend on the iteration order of the map
+			for i := 0; i < 10; i++ {
+				out, err := addJSONKey(in, row.jsonKey)
+				if row.expErr {
+					if err == nil {
+						t.Fatalf("expected an error, got %v", out)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got := string(out[row.jsonKey]); got != row.expected {
+					t.Fatalf("unexpected json: got %s, expected %s", got, row.expected)
+				}
+				if len(out) != len(row.data)+1 {
+					t.Fatalf("unexpected keys: %v", out)
+				}
+				for k, v := range row.data {
+					if string(out[k]) != string(v) {
+						t.Fatalf("unexpected value of key %q: %q", k, out[k])
+					}
+				}
+			}
+			if _, ok := in[row.jsonKey]; ok {
+				t.Fatalf("input was modified")
+			}
+		})
+	}
+}
+
+func TestFilterDataFromKeys(t *testing.T) {
+	data := map[string][]byte{
+		"tls.crt":     []byte("cert"),
+		"tlsXcrt":     []byte("other"),
+		"tls.key":     []byte("key"),
+		"db_user":     []byte("admin"),
+		"db_password": []byte("secret"),
+		"db.password": []byte("dotted"),
+	}
+	tbl := []struct {
+		test     string
+		include  []string
+		exclude  []string
+		expected []string
+		expErr   bool
+	}{
+		{
+			test:     "key names include the exact key",
+			include:  []string{"tls.crt"},
+			expected: []string{"tls.crt"},
+		},
+		{
+			test:     "regexp entries match the whole key",
+			include:  []string{"regexp:tls\\..*", "regexp:db"},
+			expected: []string{"tls.crt", "tls.key"},
+		},
+		{
+			test:     "key names exclude the exact key",
+			exclude:  []string{"db.password", "tls.crt"},
+			expected: []string{"tlsXcrt", "tls.key", "db_user", "db_password"},
+		},
+		{
+			test:     "regexp entries exclude every matching key",
+			exclude:  []string{"regexp:db.password", "regexp:tls.*"},
+			expected: []string{"db_user"},
+		},
+		{
+			test:     "exclude is evaluated after include",
+			include:  []string{"regexp:db_.*", "db.password"},
+			exclude:  []string{"db.password", "db_user"},
+			expected: []string{"db_password"},
+		},
+		{
+			test:     "key names are not regular expressions",
+			include:  []string{"db_.*"},
+			expected: []string{},
+		},
+		{
+			test:    "invalid regexp entries are rejected",
+			include: []string{"regexp:db_("},
+			expErr:  true,
+		},
+	}
+
+	for _, row := range tbl {
+		t.Run(row.test, func(t *testing.T) {
+			out, err := filterDataFromKeys(data, esv1alpha1.ExternalSecretDataFromRemoteRef{
+				Include: row.include,
+				Exclude: row.exclude,
+			})
+			if row.expErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			keys := make([]string, 0, len(out))
+			for k := range out {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			sort.Strings(row.expected)
+			if strings.Join(keys, ",") != strings.Join(row.expected, ",") {
+				t.Fatalf("unexpected keys: got %v, expected %v", keys, row.expected)
+			}
+		})
+	}
 }
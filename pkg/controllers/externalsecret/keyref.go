@@ -0,0 +1,167 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package externalsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+// keyRefError is returned if the key of a remote ref can not be read from its keyFrom source.
+type keyRefError struct {
+	err error
+}
+
+func (e *keyRefError) Error() string {
+	return fmt.Sprintf("could not read key from keyFrom: %s", e.err)
+}
+
+func (e *keyRefError) Unwrap() error {
+	return e.err
+}
+
+func isKeyRefError(err error) bool {
+	var keyRef *keyRefError
+	return errors.As(err, &keyRef)
+}
+
+// keyResolvingClient reads the keys of remote refs with keyFrom
+// from ConfigMaps or Secrets before the provider is called.
+type keyResolvingClient struct {
+	provider.SecretsClient
+	kube      client.Client
+	namespace string
+}
+
+// withKeyResolution wraps the client of the store, so keyFrom is resolved
+// in the namespace of the ExternalSecret.
+func withKeyResolution(kube client.Client, namespace string, secretClient provider.SecretsClient) provider.SecretsClient {
+	return &keyResolvingClient{
+		SecretsClient: secretClient,
+		kube:          kube,
+		namespace:     namespace,
+	}
+}
+
+func (c *keyResolvingClient) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+	ref, err := c.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return c.SecretsClient.GetSecret(ctx, ref)
+}
+
+func (c *keyResolvingClient) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	ref, err := c.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return c.SecretsClient.GetSecretMap(ctx, ref)
+}
+
+func (c *keyResolvingClient) Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	ref, err := c.resolve(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	return c.SecretsClient.Exists(ctx, ref)
+}
+
+// resolve returns the ref with the key read from keyFrom.
+func (c *keyResolvingClient) resolve(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (esv1alpha1.ExternalSecretDataRemoteRef, error) {
+	if ref.KeyFrom == nil {
+		return ref, nil
+	}
+	key, err := c.readKey(ctx, ref.KeyFrom)
+	if err != nil {
+		return ref, &keyRefError{err: err}
+	}
+	ref.Key = key
+	ref.KeyFrom = nil
+	return ref, nil
+}
+
+func (c *keyResolvingClient) readKey(ctx context.Context, src *esv1alpha1.RemoteKeySource) (string, error) {
+	var kind, name, key, value string
+	switch {
+	case src.ConfigMapKeyRef != nil && src.SecretKeyRef != nil:
+		return "", fmt.Errorf("only one of configMapKeyRef and secretKeyRef can be set")
+	case src.ConfigMapKeyRef != nil:
+		kind, name, key = "ConfigMap", src.ConfigMapKeyRef.Name, src.ConfigMapKeyRef.Key
+		var configMap corev1.ConfigMap
+		if err := c.kube.Get(ctx, client.ObjectKey{Name: name, Namespace: c.namespace}, &configMap); err != nil {
+			return "", fmt.Errorf("could not get ConfigMap %q: %w", name, err)
+		}
+		value = configMap.Data[key]
+	case src.SecretKeyRef != nil:
+		kind, name, key = "Secret", src.SecretKeyRef.Name, src.SecretKeyRef.Key
+		var secret corev1.Secret
+		if err := c.kube.Get(ctx, client.ObjectKey{Name: name, Namespace: c.namespace}, &secret); err != nil {
+			return "", fmt.Errorf("could not get Secret %q: %w", name, err)
+		}
+		value = string(secret.Data[key])
+	default:
+		return "", fmt.Errorf("one of configMapKeyRef and secretKeyRef must be set")
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("key %q of %s %q is missing or empty", key, kind, name)
+	}
+	return value, nil
+}
+
+// referencesKeyFrom returns true if a remote ref of the ExternalSecret reads its key
+// from the ConfigMap (configMap is true) or Secret with the given name.
+func referencesKeyFrom(es *esv1alpha1.ExternalSecret, name string, configMap bool) bool {
+	for _, ref := range remoteRefs(es) {
+		if ref.KeyFrom == nil {
+			continue
+		}
+		if configMap && ref.KeyFrom.ConfigMapKeyRef != nil && ref.KeyFrom.ConfigMapKeyRef.Name == name {
+			return true
+		}
+		if !configMap && ref.KeyFrom.SecretKeyRef != nil && ref.KeyFrom.SecretKeyRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteRefs returns all remote refs of the ExternalSecret.
+func remoteRefs(es *esv1alpha1.ExternalSecret) []esv1alpha1.ExternalSecretDataRemoteRef {
+	var refs []esv1alpha1.ExternalSecretDataRemoteRef
+	for _, data := range es.Spec.Data {
+		refs = append(refs, data.RemoteRef)
+	}
+	for _, dataFrom := range es.Spec.DataFrom {
+		refs = append(refs, dataFrom.ExternalSecretDataRemoteRef)
+	}
+	for _, assembly := range es.Spec.Target.Assemble {
+		refs = append(refs, assembly.RemoteRefs...)
+	}
+	for _, composition := range es.Spec.Target.Compose {
+		for _, field := range composition.Fields {
+			refs = append(refs, field.RemoteRef)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,203 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package externalsecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+)
+
+const (
+	// replicaFinalizer keeps the ExternalSecret until its copies in other namespaces are deleted.
+	replicaFinalizer = "external-secrets.io/replicas"
+
+	// replicaOwnerLabel holds the UID of the ExternalSecret a copy belongs to.
+	// Owner references can not point to other namespaces, so copies are tracked with this label.
+	replicaOwnerLabel = "external-secrets.io/owner-uid"
+)
+
+// namespaceNotAllowedError is returned when spec.target.namespaces contains a
+// namespace that is not allowed by the operator.
+type namespaceNotAllowedError struct {
+	namespace string
+}
+
+func (e *namespaceNotAllowedError) Error() string {
+	return fmt.Sprintf("copying the secret to namespace %q is not allowed", e.namespace)
+}
+
+// checkTargetNamespaces fails if the Secret should be copied to a namespace
+// that is not in the allowlist of the operator.
+func (r *Reconciler) checkTargetNamespaces(externalSecret *esv1alpha1.ExternalSecret) error {
+	for _, namespace := range externalSecret.Spec.Target.Namespaces {
+		if namespace != externalSecret.Namespace && !r.targetNamespaceAllowed(namespace) {
+			return &namespaceNotAllowedError{namespace: namespace}
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) targetNamespaceAllowed(namespace string) bool {
+	for _, allowed := range r.AllowedTargetNamespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// replicateSecret copies the synced Secret to the namespaces of spec.target.namespaces
+// and deletes copies in namespaces that were removed from the list.
+func (r *Reconciler) replicateSecret(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret, secret *corev1.Secret) error {
+	keep := make(map[string]bool)
+	for _, namespace := range externalSecret.Spec.Target.Namespaces {
+		if namespace != externalSecret.Namespace {
+			keep[namespace] = true
+		}
+	}
+
+	// the finalizer is added first, so copies are never left behind
+	// and an ExternalSecret without the finalizer has no copies
+	if len(keep) == 0 && !controllerutil.ContainsFinalizer(externalSecret, replicaFinalizer) {
+		return nil
+	}
+	if err := r.setReplicaFinalizer(ctx, externalSecret, true); err != nil {
+		return err
+	}
+	for namespace := range keep {
+		if err := r.writeReplica(ctx, externalSecret, secret, namespace); err != nil {
+			return err
+		}
+	}
+	if err := r.deleteReplicas(ctx, externalSecret, keep); err != nil {
+		return err
+	}
+	if len(keep) == 0 {
+		return r.setReplicaFinalizer(ctx, externalSecret, false)
+	}
+	return nil
+}
+
+// writeReplica creates or updates the copy of the Secret in the namespace.
+// Existing Secrets that are not a copy of this ExternalSecret are not overwritten.
+// Immutable copies whose data changed are deleted and created again.
+func (r *Reconciler) writeReplica(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret, secret *corev1.Secret, namespace string) error {
+	replica := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: namespace,
+		},
+	}
+	_, err := ctrl.CreateOrUpdate(ctx, r.Client, replica, func() error {
+		if replica.ResourceVersion != "" && replica.Labels[replicaOwnerLabel] != string(externalSecret.UID) {
+			return fmt.Errorf("secret %q in namespace %q is not managed by this ExternalSecret", replica.Name, namespace)
+		}
+		if immutableChanged(replica, secret) {
+			return errImmutableSecretChanged
+		}
+		copySecret(externalSecret, secret, replica)
+		return nil
+	})
+	if errors.Is(err, errImmutableSecretChanged) {
+		err = r.Delete(ctx, replica, client.Preconditions{UID: &replica.UID, ResourceVersion: &replica.ResourceVersion})
+		if err == nil || apierrors.IsNotFound(err) {
+			replica = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secret.Name,
+					Namespace: namespace,
+				},
+			}
+			copySecret(externalSecret, secret, replica)
+			err = r.Create(ctx, replica)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not copy secret to namespace %q: %w", namespace, err)
+	}
+	return nil
+}
+
+// copySecret copies the labels, annotations, type, immutability and data of the Secret to its copy.
+func copySecret(externalSecret *esv1alpha1.ExternalSecret, secret, replica *corev1.Secret) {
+	replica.Labels = make(map[string]string, len(secret.Labels)+1)
+	for k, v := range secret.Labels {
+		replica.Labels[k] = v
+	}
+	replica.Labels[replicaOwnerLabel] = string(externalSecret.UID)
+	replica.Annotations = secret.Annotations
+	replica.Type = secret.Type
+	replica.Immutable = secret.Immutable
+	replica.Data = secret.Data
+}
+
+// deleteReplicas deletes the copies of the Secret in all namespaces except the kept ones.
+func (r *Reconciler) deleteReplicas(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret, keep map[string]bool) error {
+	var replicas corev1.SecretList
+	err := r.List(ctx, &replicas, client.MatchingLabels{replicaOwnerLabel: string(externalSecret.UID)})
+	if err != nil {
+		return fmt.Errorf("could not list secret copies: %w", err)
+	}
+	for i := range replicas.Items {
+		replica := &replicas.Items[i]
+		if keep[replica.Namespace] {
+			continue
+		}
+		if err := r.Delete(ctx, replica); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete secret copy in namespace %q: %w", replica.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// finalizeReplicas deletes all copies of the Secret of a deleted ExternalSecret
+// and removes the finalizer.
+func (r *Reconciler) finalizeReplicas(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret) error {
+	if !controllerutil.ContainsFinalizer(externalSecret, replicaFinalizer) {
+		return nil
+	}
+	if err := r.deleteReplicas(ctx, externalSecret, nil); err != nil {
+		return err
+	}
+	return r.setReplicaFinalizer(ctx, externalSecret, false)
+}
+
+// setReplicaFinalizer adds or removes the finalizer. A copy of the ExternalSecret is
+// patched, so the status that was set during the reconcile is not overwritten.
+func (r *Reconciler) setReplicaFinalizer(ctx context.Context, externalSecret *esv1alpha1.ExternalSecret, add bool) error {
+	if controllerutil.ContainsFinalizer(externalSecret, replicaFinalizer) == add {
+		return nil
+	}
+	patched := externalSecret.DeepCopy()
+	if add {
+		controllerutil.AddFinalizer(patched, replicaFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(patched, replicaFinalizer)
+	}
+	if err := r.Patch(ctx, patched, client.MergeFrom(externalSecret)); err != nil {
+		return fmt.Errorf("could not update finalizers: %w", err)
+	}
+	externalSecret.Finalizers = patched.Finalizers
+	externalSecret.ResourceVersion = patched.ResourceVersion
+	return nil
+}
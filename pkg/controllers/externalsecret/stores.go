@@ -0,0 +1,86 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package externalsecret
+
+import (
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+// storeClients serves the data and dataFrom entries that set their own secretStoreRef.
+// All other calls go to the client of the store of the ExternalSecret.
+type storeClients struct {
+	provider.SecretsClient
+	stores map[esv1alpha1.SecretStoreRef]provider.SecretsClient
+}
+
+// clientForEntry returns the client of the store of a data or dataFrom entry.
+// Entries without secretStoreRef use the store of the ExternalSecret.
+func clientForEntry(secretClient provider.SecretsClient, ref *esv1alpha1.SecretStoreRef) provider.SecretsClient {
+	clients, ok := secretClient.(*storeClients)
+	if !ok || ref == nil {
+		return secretClient
+	}
+	if entryClient, ok := clients.stores[*ref]; ok {
+		return entryClient
+	}
+	return secretClient
+}
+
+// entryStoreRefs returns the distinct stores referenced by the data and dataFrom entries
+// except the store of the ExternalSecret.
+func entryStoreRefs(es *esv1alpha1.ExternalSecret) []esv1alpha1.SecretStoreRef {
+	var refs []esv1alpha1.SecretStoreRef
+	seen := map[esv1alpha1.SecretStoreRef]bool{es.Spec.SecretStoreRef: true}
+	add := func(ref *esv1alpha1.SecretStoreRef) {
+		if ref == nil || seen[*ref] {
+			return
+		}
+		seen[*ref] = true
+		refs = append(refs, *ref)
+	}
+	for i := range es.Spec.Data {
+		add(es.Spec.Data[i].SecretStoreRef)
+	}
+	for i := range es.Spec.DataFrom {
+		add(es.Spec.DataFrom[i].SecretStoreRef)
+	}
+	return refs
+}
+
+// StoreRefs returns the distinct stores the ExternalSecret uses.
+func StoreRefs(es *esv1alpha1.ExternalSecret) []esv1alpha1.SecretStoreRef {
+	refs := entryStoreRefs(es)
+	if es.Spec.SecretStoreRef.Name != "" {
+		refs = append([]esv1alpha1.SecretStoreRef{es.Spec.SecretStoreRef}, refs...)
+	}
+	return refs
+}
+
+// usesStore returns true if the ExternalSecret uses one of the SecretStores
+// of namespace or one of the ClusterSecretStores.
+func usesStore(es *esv1alpha1.ExternalSecret, namespace string, storeNames, clusterStoreNames map[string]bool) bool {
+	for _, ref := range StoreRefs(es) {
+		if ref.Kind == esv1alpha1.ClusterSecretStoreKind {
+			if clusterStoreNames[ref.Name] {
+				return true
+			}
+			continue
+		}
+		if storeNames[ref.Name] && es.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -35,6 +35,9 @@ import (
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
+// allowedTargetNamespace is the only namespace ExternalSecrets may copy their Secret to.
+const allowedTargetNamespace = "ctrl-test-shared"
+
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
@@ -76,9 +79,11 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&Reconciler{
-		Client: k8sClient,
-		Scheme: k8sManager.GetScheme(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ExternalSecrets"),
+		Client:                  k8sClient,
+		Scheme:                  k8sManager.GetScheme(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("ExternalSecrets"),
+		Recorder:                k8sManager.GetEventRecorderFor("external-secrets"),
+		AllowedTargetNamespaces: []string{allowedTargetNamespace},
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -0,0 +1,93 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+	schema "github.com/external-secrets/external-secrets/pkg/provider/schema"
+)
+
+const (
+	// defaultValidationInterval is used if no validation interval is set.
+	defaultValidationInterval = 5 * time.Minute
+
+	errGetProvider = "could not get store provider: %w"
+	errNewClient   = "could not create provider client: %w"
+)
+
+// reconcile validates a SecretStore or ClusterSecretStore and records the
+// result and the authentication method in its status.
+func reconcile(ctx context.Context, log logr.Logger, kubeClient client.Client, store esv1alpha1.GenericStore, controllerClass string, interval time.Duration) ctrl.Result {
+	// check if store should be handled by this controller instance
+	if store.GetSpec().Controller != "" && store.GetSpec().Controller != controllerClass {
+		return ctrl.Result{}
+	}
+
+	condition := NewSecretStoreCondition(esv1alpha1.SecretStoreReady, corev1.ConditionTrue, esv1alpha1.ConditionReasonStoreValidated, "store validated")
+	authMethod, err := validateStore(ctx, kubeClient, store)
+	if err != nil {
+		log.Error(err, "store validation failed")
+		condition = NewSecretStoreCondition(esv1alpha1.SecretStoreReady, corev1.ConditionFalse, esv1alpha1.ConditionReasonStoreValidationFailed, err.Error())
+	}
+	SetSecretStoreCondition(store, *condition)
+	store.GetStatus().AuthMethod = authMethod
+	store.GetStatus().LastCheckTime = metav1.Now()
+	if err := kubeClient.Status().Update(ctx, store); err != nil {
+		log.Error(err, "unable to update status")
+	}
+
+	return ctrl.Result{RequeueAfter: validationInterval(interval)}
+}
+
+// validateStore creates a client for the store. If the client can validate
+// the store configuration with the provider, it is validated as well.
+// It returns the authentication method the client was created with, if any.
+func validateStore(ctx context.Context, kubeClient client.Client, store esv1alpha1.GenericStore) (string, error) {
+	storeProvider, err := schema.GetProvider(store)
+	if err != nil {
+		return "", fmt.Errorf(errGetProvider, err)
+	}
+	secretClient, err := storeProvider.NewClient(ctx, store, kubeClient, store.GetNamespace())
+	if err != nil {
+		return "", fmt.Errorf(errNewClient, err)
+	}
+	if validator, ok := secretClient.(provider.Validator); ok {
+		if err := validator.Validate(ctx); err != nil {
+			return "", err
+		}
+	}
+	if reporter, ok := secretClient.(provider.AuthMethodReporter); ok {
+		return reporter.AuthMethod(), nil
+	}
+	return "", nil
+}
+
+func validationInterval(interval time.Duration) time.Duration {
+	if interval > 0 {
+		return interval
+	}
+	return defaultValidationInterval
+}
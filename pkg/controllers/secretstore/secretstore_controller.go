@@ -16,13 +16,19 @@ package secretstore
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+
+	// Loading registered providers.
+	_ "github.com/external-secrets/external-secrets/pkg/provider/register"
 )
 
 // Reconciler reconciles a SecretStore object.
@@ -31,19 +37,27 @@ type Reconciler struct {
 	Log             logr.Logger
 	Scheme          *runtime.Scheme
 	ControllerClass string
+	// ValidationInterval is the time between two validations of a store.
+	// Status updates do not trigger a validation, so the provider is not
+	// contacted more often unless the spec of the store changes.
+	ValidationInterval time.Duration
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
-	_ = r.Log.WithValues("secretstore", req.NamespacedName)
+	log := r.Log.WithValues("secretstore", req.NamespacedName)
 
-	// your logic here
+	var store esv1alpha1.SecretStore
+	err := r.Get(ctx, req.NamespacedName, &store)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
 
-	return ctrl.Result{}, nil
+	return reconcile(ctx, log, r.Client, &store, r.ControllerClass, r.ValidationInterval), nil
 }
 
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&esv1alpha1.SecretStore{}).
+		// status updates must not trigger a validation
+		For(&esv1alpha1.SecretStore{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
@@ -0,0 +1,164 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider/fake"
+	"github.com/external-secrets/external-secrets/pkg/provider/schema"
+)
+
+var (
+	fakeProvider *fake.Client
+	timeout      = time.Second * 30
+	interval     = time.Millisecond * 250
+)
+
+var _ = Describe("SecretStore controller", func() {
+	const (
+		SecretStoreName      = "test-store"
+		SecretStoreNamespace = "default"
+	)
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(context.Background(), &esv1alpha1.SecretStore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      SecretStoreName,
+				Namespace: SecretStoreNamespace,
+			},
+		})).To(Succeed())
+	})
+
+	Context("When validating a SecretStore", func() {
+		It("should set the Ready condition to False when the provider starts failing", func() {
+			ctx := context.Background()
+			fakeProvider.WithValidate(nil)
+			Expect(k8sClient.Create(ctx, &esv1alpha1.SecretStore{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      SecretStoreName,
+					Namespace: SecretStoreNamespace,
+				},
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							Service: esv1alpha1.AWSServiceSecretsManager,
+						},
+					},
+				},
+			})).To(Succeed())
+
+			storeLookupKey := types.NamespacedName{
+				Name:      SecretStoreName,
+				Namespace: SecretStoreNamespace,
+			}
+			readyStatus := func() (v1.ConditionStatus, string) {
+				store := &esv1alpha1.SecretStore{}
+				if err := k8sClient.Get(ctx, storeLookupKey, store); err != nil {
+					return "", ""
+				}
+				cond := GetSecretStoreCondition(store.Status, esv1alpha1.SecretStoreReady)
+				if cond == nil || store.Status.LastCheckTime.IsZero() {
+					return "", ""
+				}
+				return cond.Status, cond.Message
+			}
+			Eventually(func() v1.ConditionStatus {
+				status, _ := readyStatus()
+				return status
+			}, timeout, interval).Should(Equal(v1.ConditionTrue))
+
+			fakeProvider.WithValidate(fmt.Errorf("some api err"))
+			Eventually(func() bool {
+				status, message := readyStatus()
+				return status == v1.ConditionFalse && message == "some api err"
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("ClusterSecretStore controller", func() {
+	const ClusterSecretStoreName = "test-cluster-store"
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(context.Background(), &esv1alpha1.ClusterSecretStore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ClusterSecretStoreName,
+			},
+		})).To(Succeed())
+	})
+
+	Context("When validating a ClusterSecretStore", func() {
+		It("should set the Ready condition to False when the provider starts failing", func() {
+			ctx := context.Background()
+			fakeProvider.WithValidate(nil)
+			Expect(k8sClient.Create(ctx, &esv1alpha1.ClusterSecretStore{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ClusterSecretStoreName,
+				},
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							Service: esv1alpha1.AWSServiceSecretsManager,
+						},
+					},
+				},
+			})).To(Succeed())
+
+			storeLookupKey := types.NamespacedName{
+				Name: ClusterSecretStoreName,
+			}
+			readyStatus := func() (v1.ConditionStatus, string) {
+				store := &esv1alpha1.ClusterSecretStore{}
+				if err := k8sClient.Get(ctx, storeLookupKey, store); err != nil {
+					return "", ""
+				}
+				cond := GetSecretStoreCondition(store.Status, esv1alpha1.SecretStoreReady)
+				if cond == nil || store.Status.LastCheckTime.IsZero() {
+					return "", ""
+				}
+				return cond.Status, cond.Message
+			}
+			Eventually(func() v1.ConditionStatus {
+				status, _ := readyStatus()
+				return status
+			}, timeout, interval).Should(Equal(v1.ConditionTrue))
+
+			fakeProvider.WithValidate(fmt.Errorf("some api err"))
+			Eventually(func() bool {
+				status, message := readyStatus()
+				return status == v1.ConditionFalse && message == "some api err"
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+})
+
+func init() {
+	fakeProvider = fake.New()
+	schema.ForceRegister(fakeProvider, &esv1alpha1.SecretStoreProvider{
+		AWS: &esv1alpha1.AWSProvider{
+			Service: esv1alpha1.AWSServiceSecretsManager,
+		},
+	})
+}
@@ -0,0 +1,236 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mapformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	errUnwrapJSONString = "unable to unwrap JSON string: %w"
+	errNestedJSONString = "unable to unwrap JSON string: value is encoded as JSON string more than once"
+)
+
+// ParseJSON parses a JSON object with string values. It returns the same values
+// and errors as json.Unmarshal into a map[string]string, but objects that only
+// hold strings are read without the reflection of encoding/json.
+func ParseJSON(data []byte) (map[string][]byte, error) {
+	if secretData, ok := parseJSONStrings(data); ok {
+		return secretData, nil
+	}
+	kv := make(map[string]string)
+	err := json.Unmarshal(data, &kv)
+	if err != nil {
+		return nil, err
+	}
+	secretData := make(map[string][]byte, len(kv))
+	for k, v := range kv {
+		secretData[k] = []byte(v)
+	}
+	return secretData, nil
+}
+
+// UnwrapJSONString decodes a value that holds a JSON document encoded as JSON string.
+// Other values are returned unchanged. Only one level is unwrapped, values that are still
+// a JSON string afterwards are rejected instead of being decoded until something else shows up.
+func UnwrapJSONString(data []byte) ([]byte, error) {
+	if !isJSONString(data) {
+		return data, nil
+	}
+	var unwrapped string
+	if err := json.Unmarshal(data, &unwrapped); err != nil {
+		return nil, fmt.Errorf(errUnwrapJSONString, err)
+	}
+	if isJSONString([]byte(unwrapped)) {
+		return nil, fmt.Errorf(errNestedJSONString)
+	}
+	return []byte(unwrapped), nil
+}
+
+func isJSONString(data []byte) bool {
+	i := skipSpace(data, 0)
+	return i < len(data) && data[i] == '"'
+}
+
+// parseJSONStrings reads an object of string values in a single pass. It gives up
+// on everything else, like invalid JSON, other values or invalid UTF-8 which
+// encoding/json replaces, so these cases are left to encoding/json.
+func parseJSONStrings(data []byte) (map[string][]byte, bool) {
+	i := skipSpace(data, 0)
+	if i == len(data) || data[i] != '{' {
+		return nil, false
+	}
+	secretData := make(map[string][]byte)
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return secretData, skipSpace(data, i+1) == len(data)
+	}
+	for {
+		key, next, ok := scanString(data, i)
+		if !ok {
+			return nil, false
+		}
+		i = skipSpace(data, next)
+		if i == len(data) || data[i] != ':' {
+			return nil, false
+		}
+		value, next, ok := scanString(data, skipSpace(data, i+1))
+		if !ok {
+			return nil, false
+		}
+		secretData[string(key)] = append(make([]byte, 0, len(value)), value...)
+		i = skipSpace(data, next)
+		if i == len(data) {
+			return nil, false
+		}
+		if data[i] == '}' {
+			return secretData, skipSpace(data, i+1) == len(data)
+		}
+		if data[i] != ',' {
+			return nil, false
+		}
+		i = skipSpace(data, i+1)
+	}
+}
+
+// scanString returns the value of the JSON string at data[i] and the position
+// after it. The value may share its memory with data.
+func scanString(data []byte, i int) ([]byte, int, bool) {
+	if i == len(data) || data[i] != '"' {
+		return nil, 0, false
+	}
+	end := bytes.IndexByte(data[i+1:], '"')
+	if end < 0 {
+		return nil, 0, false
+	}
+	raw := data[i+1 : i+1+end]
+	if isPlainASCII(raw) {
+		return raw, i + end + 2, true
+	}
+	return scanSpecialString(data, i)
+}
+
+// isPlainASCII returns true if the string contains no escape sequences,
+// control characters or multi byte characters.
+func isPlainASCII(raw []byte) bool {
+	for _, c := range raw {
+		if c < ' ' || c == '\\' || c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// scanSpecialString is the slow path of scanString for strings with escape
+// sequences or multi byte characters.
+func scanSpecialString(data []byte, i int) ([]byte, int, bool) {
+	escaped, nonASCII := false, false
+	for j := i + 1; j < len(data); j++ {
+		switch c := data[j]; {
+		case c == '"':
+			raw := data[i+1 : j]
+			if nonASCII && !utf8.Valid(raw) {
+				return nil, 0, false
+			}
+			if escaped {
+				value, ok := unescape(raw)
+				return value, j + 1, ok
+			}
+			return raw, j + 1, true
+		case c == '\\':
+			escaped = true
+			j++
+		case c < ' ':
+			return nil, 0, false
+		case c >= utf8.RuneSelf:
+			nonASCII = true
+		}
+	}
+	return nil, 0, false
+}
+
+// unescape decodes the escape sequences of a JSON string. Surrogates are left
+// to encoding/json, which replaces invalid pairs.
+func unescape(raw []byte) ([]byte, bool) {
+	value := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' {
+			value = append(value, raw[i])
+			continue
+		}
+		i++
+		if i == len(raw) {
+			return nil, false
+		}
+		switch raw[i] {
+		case '"', '\\', '/':
+			value = append(value, raw[i])
+		case 'b':
+			value = append(value, '\b')
+		case 'f':
+			value = append(value, '\f')
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 't':
+			value = append(value, '\t')
+		case 'u':
+			r, ok := hexRune(raw[i+1:])
+			if !ok || utf16.IsSurrogate(r) {
+				return nil, false
+			}
+			var buf [utf8.UTFMax]byte
+			value = append(value, buf[:utf8.EncodeRune(buf[:], r)]...)
+			i += 4
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// hexRune parses the four hex digits of a \u escape sequence.
+func hexRune(digits []byte) (rune, bool) {
+	if len(digits) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range digits[:4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r*16 + rune(c)
+	}
+	return r, true
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
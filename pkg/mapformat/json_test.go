@@ -0,0 +1,158 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mapformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// unmarshalStrings is how JSON maps were parsed before ParseJSON,
+// the output of ParseJSON has to stay the same.
+func unmarshalStrings(data []byte) (map[string][]byte, error) {
+	kv := make(map[string]string)
+	err := json.Unmarshal(data, &kv)
+	if err != nil {
+		return nil, err
+	}
+	secretData := make(map[string][]byte)
+	for k, v := range kv {
+		secretData[k] = []byte(v)
+	}
+	return secretData, nil
+}
+
+func TestParseJSON(t *testing.T) {
+	tbl := []struct {
+		test string
+		data string
+	}{
+		{test: "strings", data: `{"user": "admin", "password": "s3cr=t"}`},
+		{test: "empty object", data: `{}`},
+		{test: "whitespace", data: " \n\t{ \"a\" :\n\"b\" } \n"},
+		{test: "escapes", data: `{"pem": "-----BEGIN-----\nabc\n-----END-----", "quote": "say \"hi\"", "slash": "a\/b"}`},
+		{test: "escaped key", data: `{"a\tb": "c", "é": "d"}`},
+		{test: "unicode escapes", data: `{"emoji": "\ud83d\ude00", "accent": "\u00e9t\u00E9", "nul": "\u0000"}`},
+		{test: "short unicode escape", data: `{"a": "\u12"}`},
+		{test: "escaped backslash at the end", data: `{"a": "b\\"}`},
+		{test: "invalid surrogate", data: `{"lone": "\ud83d", "reversed": "\ude00\ud83d"}`},
+		{test: "utf-8", data: `{"greeting": "grüße 世界"}`},
+		{test: "invalid utf-8", data: "{\"bin\": \"a\xffb\"}"},
+		{test: "duplicate keys", data: `{"a": "first", "a": "second"}`},
+		{test: "empty key and value", data: `{"": ""}`},
+		{test: "null value", data: `{"a": null, "b": "c"}`},
+		{test: "null", data: `null`},
+		{test: "number value", data: `{"a": 1}`},
+		{test: "bool value", data: `{"a": true}`},
+		{test: "nested object", data: `{"a": {"b": "c"}}`},
+		{test: "array", data: `["a", "b"]`},
+		{test: "string", data: `"a"`},
+		{test: "empty", data: ``},
+		{test: "invalid", data: `{"a": "b"`},
+		{test: "trailing data", data: `{"a": "b"} {}`},
+		{test: "control character", data: "{\"a\": \"b\tc\"}"},
+		{test: "invalid escape", data: `{"a": "\x"}`},
+		{test: "single quotes", data: `{'a': 'b'}`},
+		{test: "large", data: string(largeJSON(1000))},
+	}
+
+	for _, row := range tbl {
+		t.Run(row.test, func(t *testing.T) {
+			expected, expectedErr := unmarshalStrings([]byte(row.data))
+			got, err := ParseJSON([]byte(row.data))
+			if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+				t.Fatalf("unexpected error: got %v, expected %v", err, expectedErr)
+			}
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("unexpected secret data (-expected, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// largeJSON returns an object with n string values of about 300 bytes each,
+// some of them with escape sequences.
+func largeJSON(n int) []byte {
+	kv := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		value := strings.Repeat(fmt.Sprintf("value-%d-", i), 30)
+		if i%10 == 0 {
+			value = fmt.Sprintf("-----BEGIN-----\n%s\n-----END-----", value)
+		}
+		kv[fmt.Sprintf("key-%d", i)] = value
+	}
+	data, _ := json.Marshal(kv)
+	return data
+}
+
+func TestUnwrapJSONString(t *testing.T) {
+	tbl := []struct {
+		test     string
+		data     string
+		expected string
+		err      string
+	}{
+		{test: "double encoded", data: `"{\"foo\":\"bar\"}"`, expected: `{"foo":"bar"}`},
+		{test: "double encoded with whitespace", data: " \n\"{\\\"foo\\\": \\\"bar\\\"}\" ", expected: `{"foo": "bar"}`},
+		{test: "object is left as it is", data: `{"foo":"bar"}`, expected: `{"foo":"bar"}`},
+		{test: "invalid json is left as it is", data: `not json`, expected: `not json`},
+		{test: "triple encoded", data: `"\"{\\\"foo\\\":\\\"bar\\\"}\""`, err: errNestedJSONString},
+		{test: "unterminated string", data: `"{\"foo\"`, err: "unable to unwrap JSON string: unexpected end of JSON input"},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			out, err := UnwrapJSONString([]byte(row.data))
+			if row.err != "" {
+				if err == nil || !strings.Contains(err.Error(), row.err) {
+					t.Errorf("unexpected error: %v, expected: %s", err, row.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if string(out) != row.expected {
+				t.Errorf("unexpected result: %s, expected: %s", out, row.expected)
+			}
+		})
+	}
+}
+
+func BenchmarkParseJSON(b *testing.B) {
+	data := largeJSON(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseJSON(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalStrings(b *testing.B) {
+	data := largeJSON(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := unmarshalStrings(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
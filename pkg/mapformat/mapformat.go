@@ -0,0 +1,82 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mapformat parses Provider values into Secret keys.
+package mapformat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+)
+
+const (
+	errUnknownFormat       = "unknown map format %q"
+	errPropertiesSeparator = "unable to parse properties: line %d has no '=' or ':' separator"
+	errPropertiesEmptyKey  = "unable to parse properties: line %d has an empty key"
+	errPropertiesSection   = "unable to parse properties: line %d has an invalid section header"
+	errPropertiesRead      = "unable to parse properties: %w"
+)
+
+// IsJSON returns true if the format is JSON, which is the default.
+func IsJSON(format esv1alpha1.MapFormat) bool {
+	return format == "" || format == esv1alpha1.MapFormatJSON
+}
+
+// Parse parses data in the given format. JSON is parsed with ParseJSON,
+// so only the other formats are supported.
+func Parse(format esv1alpha1.MapFormat, data []byte) (map[string][]byte, error) {
+	if format != esv1alpha1.MapFormatProperties {
+		return nil, fmt.Errorf(errUnknownFormat, format)
+	}
+	return parseProperties(data)
+}
+
+// parseProperties reads key=value or key: value lines. Blank lines and lines
+// starting with #, ; or ! are ignored. Keys below a [section] header are
+// prefixed with "section.". Later keys overwrite earlier ones.
+func parseProperties(data []byte) (map[string][]byte, error) {
+	secretData := make(map[string][]byte)
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.TrimSpace(line[1:len(line)-1]) == "" {
+				return nil, fmt.Errorf(errPropertiesSection, lineNo)
+			}
+			section = strings.TrimSpace(line[1:len(line)-1]) + "."
+			continue
+		}
+		sep := strings.IndexAny(line, "=:")
+		if sep < 0 {
+			return nil, fmt.Errorf(errPropertiesSeparator, lineNo)
+		}
+		key := strings.TrimSpace(line[:sep])
+		if key == "" {
+			return nil, fmt.Errorf(errPropertiesEmptyKey, lineNo)
+		}
+		secretData[section+key] = []byte(strings.TrimSpace(line[sep+1:]))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(errPropertiesRead, err)
+	}
+	return secretData, nil
+}
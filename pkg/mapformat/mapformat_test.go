@@ -0,0 +1,120 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mapformat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+)
+
+func TestParse(t *testing.T) {
+	tbl := []struct {
+		test     string
+		format   esv1alpha1.MapFormat
+		data     string
+		expected map[string][]byte
+		err      string
+	}{
+		{
+			test:   "properties",
+			format: esv1alpha1.MapFormatProperties,
+			data: `# database settings
+db.user=admin
+db.password = s3cr=t
+! legacy comment
+api.token: abc
+
+url=https://example.com:8443/path
+`,
+			expected: map[string][]byte{
+				"db.user":     []byte("admin"),
+				"db.password": []byte("s3cr=t"),
+				"api.token":   []byte("abc"),
+				"url":         []byte("https://example.com:8443/path"),
+			},
+		},
+		{
+			test:   "properties with sections",
+			format: esv1alpha1.MapFormatProperties,
+			data: `global=yes
+; ini comment
+[database]
+user=admin
+password=secret
+
+[ api ]
+token=abc
+`,
+			expected: map[string][]byte{
+				"global":            []byte("yes"),
+				"database.user":     []byte("admin"),
+				"database.password": []byte("secret"),
+				"api.token":         []byte("abc"),
+			},
+		},
+		{
+			test:     "empty value",
+			format:   esv1alpha1.MapFormatProperties,
+			data:     "empty=",
+			expected: map[string][]byte{"empty": []byte("")},
+		},
+		{
+			test:   "malformed line",
+			format: esv1alpha1.MapFormatProperties,
+			data:   "user=admin\njust some text\n",
+			err:    "unable to parse properties: line 2 has no '=' or ':' separator",
+		},
+		{
+			test:   "empty key",
+			format: esv1alpha1.MapFormatProperties,
+			data:   "=value",
+			err:    "unable to parse properties: line 1 has an empty key",
+		},
+		{
+			test:   "malformed section",
+			format: esv1alpha1.MapFormatProperties,
+			data:   "[database\nuser=admin",
+			err:    "unable to parse properties: line 1 has an invalid section header",
+		},
+		{
+			test:   "json is parsed by the providers",
+			format: esv1alpha1.MapFormatJSON,
+			data:   `{"foo":"bar"}`,
+			err:    `unknown map format "json"`,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			out, err := Parse(row.format, []byte(row.data))
+			if row.err != "" {
+				if err == nil || !strings.Contains(err.Error(), row.err) {
+					t.Errorf("unexpected error: %v, expected: %s", err, row.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !cmp.Equal(out, row.expected) {
+				t.Errorf("unexpected result: %s", cmp.Diff(out, row.expected))
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package property extracts properties from JSON Provider values.
+package property
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"github.com/tidwall/gjson"
+	"k8s.io/client-go/util/jsonpath"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+)
+
+var (
+	// ErrNotFound is returned if the property does not exist in the document.
+	ErrNotFound = errors.New("property does not exist")
+
+	// ErrInvalidJSON is returned if the document is not valid JSON.
+	ErrInvalidJSON = errors.New("value is not valid JSON")
+)
+
+const (
+	errUnknownEngine   = "unknown property engine %q"
+	errParseJSONPath   = "unable to parse jsonpath %q: %w"
+	errExecuteJSONPath = "unable to execute jsonpath %q: %w"
+	errParseJQ         = "unable to parse jq %q: %w"
+	errExecuteJQ       = "unable to execute jq %q: %w"
+	errMarshalResult   = "unable to marshal property %q: %w"
+
+	// jqTimeout stops jq queries that do not terminate, like repeat(.).
+	jqTimeout = time.Second
+)
+
+// Get resolves property in the JSON document data using the given engine.
+// An empty engine defaults to gjson. Strings are returned as they are,
+// all other values are returned as JSON.
+func Get(engine esv1alpha1.PropertyEngine, data, property string) (string, error) {
+	switch engine {
+	case "", esv1alpha1.PropertyEngineGJSON:
+		// gjson does not tell a missing property apart from an invalid document
+		if !gjson.Valid(data) {
+			return "", ErrInvalidJSON
+		}
+		val := gjson.Get(data, property)
+		if !val.Exists() {
+			return "", ErrNotFound
+		}
+		return val.String(), nil
+	case esv1alpha1.PropertyEngineJSONPath:
+		return getJSONPath(data, property)
+	case esv1alpha1.PropertyEngineJQ:
+		return getJQ(data, property)
+	}
+	return "", fmt.Errorf(errUnknownEngine, engine)
+}
+
+func getJSONPath(data, property string) (string, error) {
+	jp, err := parseJSONPath(property)
+	if err != nil {
+		return "", err
+	}
+	doc, err := unmarshal(data)
+	if err != nil {
+		return "", err
+	}
+	results, err := jp.FindResults(doc)
+	if err != nil {
+		return "", fmt.Errorf(errExecuteJSONPath, property, err)
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, v := range result {
+			if v.IsValid() && v.Interface() != nil {
+				values = append(values, v.Interface())
+			}
+		}
+	}
+	switch len(values) {
+	case 0:
+		return "", ErrNotFound
+	case 1:
+		return format(property, values[0])
+	}
+	// wildcards and filters may match multiple values, like gjson queries they are returned as array
+	return format(property, values)
+}
+
+// parseJSONPath accepts expressions with and without the surrounding braces
+// kubectl uses, so $.a.b, .a.b and {.a.b} are equivalent.
+func parseJSONPath(property string) (*jsonpath.JSONPath, error) {
+	expr := property
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	jp := jsonpath.New("property").AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return nil, fmt.Errorf(errParseJSONPath, property, err)
+	}
+	return jp, nil
+}
+
+func getJQ(data, property string) (string, error) {
+	query, err := gojq.Parse(property)
+	if err != nil {
+		return "", fmt.Errorf(errParseJQ, property, err)
+	}
+	// the environment of the controller must not be readable with env or $ENV
+	code, err := gojq.Compile(query, gojq.WithEnvironLoader(func() []string { return nil }))
+	if err != nil {
+		return "", fmt.Errorf(errParseJQ, property, err)
+	}
+	doc, err := unmarshal(data)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), jqTimeout)
+	defer cancel()
+	var values []interface{}
+	iter := code.RunWithContext(ctx, doc)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return "", fmt.Errorf(errExecuteJQ, property, err)
+		}
+		// jq yields null for missing keys, so null can not be told apart from a missing property
+		if v != nil {
+			values = append(values, v)
+		}
+	}
+	switch len(values) {
+	case 0:
+		return "", ErrNotFound
+	case 1:
+		return format(property, values[0])
+	}
+	// like jsonpath, queries with multiple results are returned as array
+	return format(property, values)
+}
+
+func unmarshal(data string) (interface{}, error) {
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(data))
+	// keep numbers as they are written instead of converting them to float64
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if dec.More() {
+		return nil, ErrInvalidJSON
+	}
+	return doc, nil
+}
+
+func format(property string, val interface{}) (string, error) {
+	if s, ok := val.(string); ok {
+		return s, nil
+	}
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(val); err != nil {
+		return "", fmt.Errorf(errMarshalResult, property, err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
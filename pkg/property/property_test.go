@@ -0,0 +1,113 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package property
+
+import (
+	"errors"
+	"testing"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+)
+
+const doc = `{
+  "name": {"first": "Tom", "last": "Anderson"},
+  "age": 37,
+  "friends": [
+    {"first": "Dale", "last": "Murphy"},
+    {"first": "Roger", "last": "Craig"}
+  ],
+  "with space": "yes",
+  "nothing": null
+}`
+
+func TestGet(t *testing.T) {
+	tbl := []struct {
+		test        string
+		engine      esv1alpha1.PropertyEngine
+		data        string
+		property    string
+		expected    string
+		expectedErr error
+		expectErr   bool
+	}{
+		{test: "default engine", property: "name.first", expected: "Tom"},
+		{test: "gjson nested key", engine: esv1alpha1.PropertyEngineGJSON, property: "name.first", expected: "Tom"},
+		{test: "jsonpath nested key", engine: esv1alpha1.PropertyEngineJSONPath, property: "$.name.first", expected: "Tom"},
+		{test: "jq nested key", engine: esv1alpha1.PropertyEngineJQ, property: ".name.first", expected: "Tom"},
+
+		{test: "gjson array index", engine: esv1alpha1.PropertyEngineGJSON, property: "friends.1.first", expected: "Roger"},
+		{test: "jsonpath array index", engine: esv1alpha1.PropertyEngineJSONPath, property: "{.friends[1].first}", expected: "Roger"},
+		{test: "jq array index", engine: esv1alpha1.PropertyEngineJQ, property: ".friends[1].first", expected: "Roger"},
+		{test: "jq negative array index", engine: esv1alpha1.PropertyEngineJQ, property: ".friends[-1].first", expected: "Roger"},
+
+		{test: "gjson object", engine: esv1alpha1.PropertyEngineGJSON, property: "name", expected: `{"first": "Tom", "last": "Anderson"}`},
+		{test: "jsonpath object", engine: esv1alpha1.PropertyEngineJSONPath, property: ".name", expected: `{"first":"Tom","last":"Anderson"}`},
+		{test: "jq object", engine: esv1alpha1.PropertyEngineJQ, property: ".name", expected: `{"first":"Tom","last":"Anderson"}`},
+
+		{test: "gjson number", engine: esv1alpha1.PropertyEngineGJSON, property: "age", expected: "37"},
+		{test: "jsonpath number", engine: esv1alpha1.PropertyEngineJSONPath, property: ".age", expected: "37"},
+		{test: "jq number", engine: esv1alpha1.PropertyEngineJQ, property: ".age", expected: "37"},
+
+		{test: "jsonpath multiple results", engine: esv1alpha1.PropertyEngineJSONPath, property: ".friends[*].first", expected: `["Dale","Roger"]`},
+		{test: "jq quoted key", engine: esv1alpha1.PropertyEngineJQ, property: `."with space"`, expected: "yes"},
+		{test: "jq bracket key", engine: esv1alpha1.PropertyEngineJQ, property: `.["with space"]`, expected: "yes"},
+		{test: "jq identity", engine: esv1alpha1.PropertyEngineJQ, data: `"plain"`, property: ".", expected: "plain"},
+		{test: "jq filter", engine: esv1alpha1.PropertyEngineJQ, property: ".friends | length", expected: "2"},
+		{test: "jq select", engine: esv1alpha1.PropertyEngineJQ, property: `.friends[] | select(.last == "Craig") | .first`, expected: "Roger"},
+		{test: "jq multiple results", engine: esv1alpha1.PropertyEngineJQ, property: ".friends[].first", expected: `["Dale","Roger"]`},
+		{test: "jq environment is empty", engine: esv1alpha1.PropertyEngineJQ, property: "env", expected: "{}"},
+
+		{test: "gjson missing key", engine: esv1alpha1.PropertyEngineGJSON, property: "name.middle", expectedErr: ErrNotFound},
+		{test: "jsonpath missing key", engine: esv1alpha1.PropertyEngineJSONPath, property: ".name.middle", expectedErr: ErrNotFound},
+		{test: "jq missing key", engine: esv1alpha1.PropertyEngineJQ, property: ".name.middle", expectedErr: ErrNotFound},
+		{test: "jq index out of range", engine: esv1alpha1.PropertyEngineJQ, property: ".friends[2]", expectedErr: ErrNotFound},
+		{test: "jq null", engine: esv1alpha1.PropertyEngineJQ, property: ".nothing", expectedErr: ErrNotFound},
+
+		{test: "gjson invalid json", engine: esv1alpha1.PropertyEngineGJSON, data: "a=b", property: "a", expectedErr: ErrInvalidJSON},
+		{test: "jsonpath invalid json", engine: esv1alpha1.PropertyEngineJSONPath, data: "a=b", property: ".a", expectedErr: ErrInvalidJSON},
+		{test: "jq invalid json", engine: esv1alpha1.PropertyEngineJQ, data: "a=b", property: ".a", expectedErr: ErrInvalidJSON},
+
+		{test: "jsonpath parse error", engine: esv1alpha1.PropertyEngineJSONPath, property: ".friends[", expectErr: true},
+		{test: "jq parse error", engine: esv1alpha1.PropertyEngineJQ, property: ".friends[", expectErr: true},
+		{test: "jq unknown function", engine: esv1alpha1.PropertyEngineJQ, property: "name", expectErr: true},
+		{test: "jq runtime error", engine: esv1alpha1.PropertyEngineJQ, property: ".age | keys", expectErr: true},
+		{test: "jq does not terminate", engine: esv1alpha1.PropertyEngineJQ, property: "repeat(.) | empty", expectErr: true},
+		{test: "unknown engine", engine: "xpath", property: "/name", expectErr: true},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			data := row.data
+			if data == "" {
+				data = doc
+			}
+			out, err := Get(row.engine, data, row.property)
+			switch {
+			case row.expectedErr != nil:
+				if !errors.Is(err, row.expectedErr) {
+					t.Errorf("unexpected error: %v, expected: %v", err, row.expectedErr)
+				}
+			case row.expectErr:
+				if err == nil {
+					t.Errorf("expected an error, got value %q", out)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case out != row.expected:
+				t.Errorf("unexpected value: %q, expected: %q", out, row.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+// maxCachedClients bounds the number of clients held by the clientCache.
+// The cache is cleared once the limit is reached.
+const maxCachedClients = 100
+
+// clientCache holds secrets clients by the configuration they were created with.
+// It is safe for concurrent use, the zero value is ready to use.
+type clientCache struct {
+	mu      sync.Mutex
+	clients map[clientCacheKey]provider.SecretsClient
+}
+
+// clientCacheKey identifies a client by the resolved store configuration.
+// Credentials are only part of the key as a hash.
+type clientCacheKey struct {
+	service          esv1alpha1.AWSServiceType
+	region           string
+	role             string
+	allowedKMSKeyIDs string
+	replicaFallback  bool
+	primaryRegion    string
+	contentTypeTags  bool
+	credentialsHash  string
+	// credentialsSource identifies the Secret keys the credentials were read from
+	credentialsSource string
+	authMethod        string
+}
+
+func newClientCacheKey(prov *esv1alpha1.AWSProvider, sak, aks string) clientCacheKey {
+	key := clientCacheKey{
+		service:          prov.Service,
+		region:           prov.Region,
+		role:             prov.Role,
+		allowedKMSKeyIDs: strings.Join(prov.AllowedKMSKeyIDs, "\n"),
+		replicaFallback:  prov.ReplicaFallback,
+		primaryRegion:    prov.PrimaryRegion,
+		contentTypeTags:  prov.ContentTypeFromTags,
+	}
+	if sak != "" || aks != "" {
+		sum := sha256.Sum256([]byte(aks + "\x00" + sak))
+		key.credentialsHash = hex.EncodeToString(sum[:])
+	}
+	return key
+}
+
+func (c *clientCache) get(key clientCacheKey) (provider.SecretsClient, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client, ok := c.clients[key]
+	return client, ok
+}
+
+func (c *clientCache) add(key clientCacheKey, client provider.SecretsClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clients == nil || len(c.clients) >= maxCachedClients {
+		c.clients = make(map[clientCacheKey]provider.SecretsClient)
+	}
+	// once the credentials of a source were rotated, the clients of the
+	// previous credentials are not used again
+	if key.credentialsSource != "" {
+		for cached := range c.clients {
+			if cached.credentialsSource == key.credentialsSource && cached.credentialsHash != key.credentialsHash {
+				delete(c.clients, cached)
+			}
+		}
+	}
+	c.clients[key] = client
+}
@@ -22,7 +22,8 @@ import (
 
 // Client implements the aws parameterstore interface.
 type Client struct {
-	valFn func(*ssm.GetParameterInput) (*ssm.GetParameterOutput, error)
+	valFn      func(*ssm.GetParameterInput) (*ssm.GetParameterOutput, error)
+	describeFn func(*ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error)
 }
 
 func (sm *Client) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
@@ -37,3 +38,16 @@ func (sm *Client) WithValue(in *ssm.GetParameterInput, val *ssm.GetParameterOutp
 		return val, err
 	}
 }
+
+func (sm *Client) DescribeParameters(in *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	return sm.describeFn(in)
+}
+
+func (sm *Client) WithDescribe(in *ssm.DescribeParametersInput, val *ssm.DescribeParametersOutput, err error) {
+	sm.describeFn = func(paramIn *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+		if !cmp.Equal(paramIn, in) {
+			return nil, fmt.Errorf("unexpected test argument")
+		}
+		return val, err
+	}
+}
@@ -15,44 +15,112 @@ package parameterstore
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/tidwall/gjson"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/mapformat"
+	"github.com/external-secrets/external-secrets/pkg/property"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+	awssess "github.com/external-secrets/external-secrets/pkg/provider/aws/session"
 )
 
 // ParameterStore is a provider for AWS ParameterStore.
 type ParameterStore struct {
 	client PMInterface
+
+	// refreshCredentials forces a refresh of the session credentials
+	// before a request that failed because of expired credentials is retried.
+	refreshCredentials func()
+
+	// credentials of the session, they are retrieved to validate the store.
+	credentials *credentials.Credentials
+
+	// identity verifies the credentials with AWS when the store is validated.
+	identity *awssess.IdentityVerifier
+
+	// authMethod is the name of the authentication method of the store.
+	authMethod string
 }
 
 // PMInterface is a subset of the parameterstore api.
 // see: https://docs.aws.amazon.com/sdk-for-go/api/service/ssm/ssmiface/
 type PMInterface interface {
 	GetParameter(*ssm.GetParameterInput) (*ssm.GetParameterOutput, error)
+	DescribeParameters(*ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error)
 }
 
+// errCodeAccessDenied is returned by AWS if the credentials are not allowed to perform a request.
+const errCodeAccessDenied = "AccessDeniedException"
+
 var log = ctrl.Log.WithName("provider").WithName("aws").WithName("parameterstore")
 
 // New constructs a ParameterStore Provider that is specific to a store.
 func New(sess client.ConfigProvider) (*ParameterStore, error) {
-	return &ParameterStore{
-		client: ssm.New(sess),
-	}, nil
+	ssmClient := ssm.New(sess)
+	pm := &ParameterStore{
+		client: ssmClient,
+	}
+	if ssmClient.Config.Credentials != nil {
+		pm.refreshCredentials = ssmClient.Config.Credentials.Expire
+		pm.credentials = ssmClient.Config.Credentials
+	}
+	return pm, nil
+}
+
+// WithIdentityVerifier sets the verifier of the credentials that is used by Validate.
+func (pm *ParameterStore) WithIdentityVerifier(identity *awssess.IdentityVerifier) *ParameterStore {
+	pm.identity = identity
+	return pm
+}
+
+// WithAuthMethod sets the name of the authentication method the client was created with.
+func (pm *ParameterStore) WithAuthMethod(name string) *ParameterStore {
+	pm.authMethod = name
+	return pm
+}
+
+// AuthMethod returns the name of the authentication method the client was created with.
+func (pm *ParameterStore) AuthMethod() string {
+	return pm.authMethod
+}
+
+// Validate checks that credentials can be retrieved, e.g. that the role of the store
+// can be assumed, and that AWS accepts them, which also detects invalid static keys.
+// Credentials are cached until they expire and are verified once, so AWS is not
+// contacted on every validation.
+func (pm *ParameterStore) Validate(ctx context.Context) error {
+	if pm.credentials == nil {
+		return nil
+	}
+	if _, err := pm.credentials.GetWithContext(ctx); err != nil {
+		return fmt.Errorf("unable to retrieve credentials: %w", awssess.WrapRequestError(err))
+	}
+	if err := pm.identity.Verify(ctx); err != nil {
+		return fmt.Errorf("unable to verify credentials: %w", err)
+	}
+	return nil
 }
 
 // GetSecret returns a single secret from the provider.
 func (pm *ParameterStore) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
 	log.Info("fetching secret value", "key", ref.Key)
-	out, err := pm.client.GetParameter(&ssm.GetParameterInput{
-		Name:           &ref.Key,
-		WithDecryption: aws.Bool(true),
+	var out *ssm.GetParameterOutput
+	err := awssess.RefreshOnExpiredCredentials(pm.refreshCredentials, func() error {
+		var err error
+		out, err = pm.client.GetParameter(&ssm.GetParameterInput{
+			Name:           &ref.Key,
+			WithDecryption: aws.Bool(true),
+		})
+		return err
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to get parameter: %w", err)
@@ -63,28 +131,81 @@ func (pm *ParameterStore) GetSecret(ctx context.Context, ref esv1alpha1.External
 		}
 		return nil, fmt.Errorf("invalid secret received. parameter value is nil for key: %s", ref.Key)
 	}
-	val := gjson.Get(*out.Parameter.Value, ref.Property)
-	if !val.Exists() {
-		return nil, fmt.Errorf("key %s does not exist in secret %s", ref.Property, ref.Key)
+	if ref.ContentType != "" && ref.ContentType != esv1alpha1.ContentTypeJSON {
+		return nil, fmt.Errorf("unable to extract property %s from secret %s with content type %s", ref.Property, ref.Key, ref.ContentType)
 	}
-	return []byte(val.String()), nil
+	if ref.ContentType == esv1alpha1.ContentTypeJSON && !gjson.Valid(*out.Parameter.Value) {
+		return nil, fmt.Errorf("secret %s with content type %s is not valid JSON", ref.Key, ref.ContentType)
+	}
+	val, err := property.Get(ref.PropertyEngine, *out.Parameter.Value, ref.Property)
+	if errors.Is(err, property.ErrNotFound) {
+		return nil, fmt.Errorf("%w: key %s does not exist in secret %s", provider.ErrPropertyNotFound, ref.Property, ref.Key)
+	}
+	if errors.Is(err, property.ErrInvalidJSON) {
+		return nil, fmt.Errorf("secret %s is not valid JSON", ref.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract property %s from secret %s: %w", ref.Property, ref.Key, err)
+	}
+	return []byte(val), nil
+}
+
+// Exists checks whether the parameter exists using DescribeParameters, which does not return the value.
+func (pm *ParameterStore) Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	var out *ssm.DescribeParametersOutput
+	err := awssess.RefreshOnExpiredCredentials(pm.refreshCredentials, func() error {
+		var err error
+		out, err = pm.client.DescribeParameters(&ssm.DescribeParametersInput{
+			ParameterFilters: []*ssm.ParameterStringFilter{
+				{
+					Key:    aws.String("Name"),
+					Option: aws.String("Equals"),
+					Values: []*string{&ref.Key},
+				},
+			},
+		})
+		return err
+	})
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == errCodeAccessDenied {
+		return false, fmt.Errorf("%w: parameter %s: %s", provider.ErrAccessDenied, ref.Key, err)
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to describe parameter: %w", err)
+	}
+	return len(out.Parameters) > 0, nil
 }
 
 // GetSecretMap returns multiple k/v pairs from the provider.
 func (pm *ParameterStore) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
 	log.Info("fetching secret map", "key", ref.Key)
+	if !mapformat.IsJSON(ref.MapFormat) {
+		data, err := pm.GetSecret(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		secretData, err := mapformat.Parse(ref.MapFormat, data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse secret %s: %w", ref.Key, err)
+		}
+		return secretData, nil
+	}
+	if ref.ContentType != "" && ref.ContentType != esv1alpha1.ContentTypeJSON {
+		return nil, fmt.Errorf("unable to use secret %s with content type %s as map", ref.Key, ref.ContentType)
+	}
 	data, err := pm.GetSecret(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
-	kv := make(map[string]string)
-	err = json.Unmarshal(data, &kv)
+	if ref.UnwrapJSONString {
+		data, err = mapformat.UnwrapJSONString(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse secret %s: %w", ref.Key, err)
+		}
+	}
+	secretData, err := mapformat.ParseJSON(data)
 	if err != nil {
 		return nil, fmt.Errorf("unable to unmarshal secret %s: %w", ref.Key, err)
 	}
-	secretData := make(map[string][]byte)
-	for k, v := range kv {
-		secretData[k] = []byte(v)
-	}
 	return secretData, nil
 }
@@ -15,16 +15,19 @@ package parameterstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
 	fake "github.com/external-secrets/external-secrets/pkg/provider/aws/parameterstore/fake"
 	sess "github.com/external-secrets/external-secrets/pkg/provider/aws/session"
 )
@@ -37,6 +40,57 @@ func TestConstructor(t *testing.T) {
 	assert.NotNil(t, c.client)
 }
 
+func TestExists(t *testing.T) {
+	in := &ssm.DescribeParametersInput{
+		ParameterFilters: []*ssm.ParameterStringFilter{
+			{
+				Key:    aws.String("Name"),
+				Option: aws.String("Equals"),
+				Values: []*string{aws.String("foo")},
+			},
+		},
+	}
+	tbl := []struct {
+		test           string
+		apiOutput      *ssm.DescribeParametersOutput
+		apiErr         error
+		expectedExists bool
+		expectedErr    error
+	}{
+		{
+			test: "parameter exists",
+			apiOutput: &ssm.DescribeParametersOutput{
+				Parameters: []*ssm.ParameterMetadata{{Name: aws.String("foo")}},
+			},
+			expectedExists: true,
+		},
+		{
+			test:      "parameter does not exist",
+			apiOutput: &ssm.DescribeParametersOutput{},
+		},
+		{
+			test:        "access denied",
+			apiErr:      awserr.New("AccessDeniedException", "not authorized to perform ssm:DescribeParameters", nil),
+			expectedErr: provider.ErrAccessDenied,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			f := &fake.Client{}
+			f.WithDescribe(in, row.apiOutput, row.apiErr)
+			p := &ParameterStore{client: f}
+			exists, err := p.Exists(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+			assert.Equal(t, row.expectedExists, exists)
+			if row.expectedErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, errors.Is(err, row.expectedErr), "unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 // test the ssm<->aws interface
 // make sure correct values are passed and errors are handled accordingly.
 func TestGetSecret(t *testing.T) {
@@ -89,6 +143,65 @@ func TestGetSecret(t *testing.T) {
 			expectError:    "",
 			expectedSecret: "bang",
 		},
+		{
+			// text/plain is stored as it is even if it looks like JSON
+			apiInput: &ssm.GetParameterInput{
+				Name:           aws.String("/baz"),
+				WithDecryption: aws.Bool(true),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:         "/baz",
+				ContentType: esv1alpha1.ContentTypeText,
+			},
+			apiOutput: &ssm.GetParameterOutput{
+				Parameter: &ssm.Parameter{
+					Value: aws.String(`{"/shmoo": "bang"}`),
+				},
+			},
+			apiErr:         nil,
+			expectError:    "",
+			expectedSecret: `{"/shmoo": "bang"}`,
+		},
+		{
+			// bad case: properties can not be extracted from text/plain
+			apiInput: &ssm.GetParameterInput{
+				Name:           aws.String("/baz"),
+				WithDecryption: aws.Bool(true),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:         "/baz",
+				Property:    "/shmoo",
+				ContentType: esv1alpha1.ContentTypeText,
+			},
+			apiOutput: &ssm.GetParameterOutput{
+				Parameter: &ssm.Parameter{
+					Value: aws.String(`{"/shmoo": "bang"}`),
+				},
+			},
+			apiErr:         nil,
+			expectError:    "unable to extract property /shmoo from secret /baz with content type text/plain",
+			expectedSecret: "",
+		},
+		{
+			// bad case: application/json must be valid JSON
+			apiInput: &ssm.GetParameterInput{
+				Name:           aws.String("/baz"),
+				WithDecryption: aws.Bool(true),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:         "/baz",
+				Property:    "/shmoo",
+				ContentType: esv1alpha1.ContentTypeJSON,
+			},
+			apiOutput: &ssm.GetParameterOutput{
+				Parameter: &ssm.Parameter{
+					Value: aws.String(`------`),
+				},
+			},
+			apiErr:         nil,
+			expectError:    "is not valid JSON",
+			expectedSecret: "",
+		},
 		{
 			// bad case: missing property
 			apiInput: &ssm.GetParameterInput{
@@ -124,7 +237,7 @@ func TestGetSecret(t *testing.T) {
 				},
 			},
 			apiErr:         nil,
-			expectError:    "key INVALPROP does not exist in secret",
+			expectError:    "secret /baz is not valid JSON",
 			expectedSecret: "",
 		},
 		{
@@ -239,6 +352,25 @@ func TestGetSecretMap(t *testing.T) {
 			apiErr:       nil,
 			expectError:  "unable to unmarshal secret",
 		},
+		{
+			// bad case: text/plain can not be used as map
+			apiInput: &ssm.GetParameterInput{
+				Name:           aws.String("/baz"),
+				WithDecryption: aws.Bool(true),
+			},
+			apiOutput: &ssm.GetParameterOutput{
+				Parameter: &ssm.Parameter{
+					Value: aws.String(`{"foo":"bar"}`),
+				},
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:         "/baz",
+				ContentType: esv1alpha1.ContentTypeText,
+			},
+			expectedData: map[string]string{},
+			apiErr:       nil,
+			expectError:  "unable to use secret /baz with content type text/plain as map",
+		},
 	} {
 		f.WithValue(row.apiInput, row.apiOutput, row.apiErr)
 		out, err := p.GetSecretMap(context.Background(), row.rr)
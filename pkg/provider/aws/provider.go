@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -26,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
 	"github.com/external-secrets/external-secrets/pkg/provider"
 	"github.com/external-secrets/external-secrets/pkg/provider/aws/parameterstore"
 	"github.com/external-secrets/external-secrets/pkg/provider/aws/secretsmanager"
@@ -34,7 +36,11 @@ import (
 )
 
 // Provider satisfies the provider interface.
-type Provider struct{}
+type Provider struct {
+	// clients holds the clients of previous calls to NewClient,
+	// so connections are reused across reconciles.
+	clients clientCache
+}
 
 var log = ctrl.Log.WithName("provider").WithName("aws")
 
@@ -55,83 +61,258 @@ const (
 	errMissingStoreSpec                        = "store is missing spec"
 	errMissingProvider                         = "storeSpec is missing provider"
 	errInvalidProvider                         = "invalid provider spec. Missing AWS field in store %s"
+	errRegionAndRegionFrom                     = "invalid provider spec: region and regionFrom can not be combined"
+	errInvalidClusterStoreMissingRegionNS      = "invalid ClusterSecretStore: missing region ConfigMap Namespace"
+	errFetchRegionConfigMap                    = "could not fetch region ConfigMap: %w"
+	errMissingRegionKey                        = "missing key %q in region ConfigMap %q"
+	errAuthAndAuthMethods                      = "invalid provider spec: auth and authMethods can not be combined"
+	errAllAuthMethodsFailed                    = "all authentication methods failed: %s"
+	errReplicaFallbackWithoutPrimaryRegion     = "invalid provider spec: replicaFallback requires primaryRegion"
 )
 
 // NewClient constructs a new secrets client based on the provided store.
+// Clients are reused as long as the store configuration and credentials do not change.
 func (p *Provider) NewClient(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string) (provider.SecretsClient, error) {
-	return newClient(ctx, store, kube, namespace, awssess.DefaultSTSProvider)
+	return newClient(ctx, store, kube, namespace, awssess.DefaultSTSProvider, &p.clients)
 }
 
-func newClient(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string, assumeRoler awssess.STSProvider) (provider.SecretsClient, error) {
-	prov, err := getAWSProvider(store)
+func newClient(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string, assumeRoler awssess.STSProvider, clients *clientCache) (provider.SecretsClient, error) {
+	prov, err := resolveProvider(ctx, store, kube, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if prov.ReplicaFallback && prov.PrimaryRegion == "" {
+		return nil, fmt.Errorf(errReplicaFallbackWithoutPrimaryRegion)
+	}
+	if len(prov.AuthMethods) > 0 {
+		return newClientWithAuthMethods(ctx, store, kube, namespace, prov, assumeRoler, clients)
+	}
+	sak, aks, err := sessionCredentials(ctx, store, kube, namespace)
+	if err != nil {
+		return nil, fmt.Errorf(errUnableCreateSession, err)
+	}
+	var source string
+	if prov.Auth != nil {
+		source = credentialsSource(store, namespace, &prov.Auth.SecretRef)
+	}
+	return cachedClient(prov, sak, aks, source, "", assumeRoler, clients)
+}
+
+// newClientWithAuthMethods tries the authentication methods of the provider in order
+// and returns a client for the first method that is able to retrieve credentials.
+func newClientWithAuthMethods(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string, prov *esv1alpha1.AWSProvider, assumeRoler awssess.STSProvider, clients *clientCache) (provider.SecretsClient, error) {
+	if prov.Auth != nil {
+		return nil, fmt.Errorf(errAuthAndAuthMethods)
+	}
+	failures := make([]string, 0, len(prov.AuthMethods))
+	for _, method := range prov.AuthMethods {
+		secretsClient, err := authMethodClient(ctx, store, kube, namespace, prov, method, assumeRoler, clients)
+		if err == nil {
+			return secretsClient, nil
+		}
+		log.V(1).Info("authentication method failed", "method", method.Name, "error", err.Error())
+		failures = append(failures, fmt.Sprintf("%s: %s", method.Name, err))
+	}
+	return nil, fmt.Errorf(errAllAuthMethodsFailed, strings.Join(failures, "; "))
+}
+
+// authMethodClient returns a client for a single authentication method.
+// The client is validated, also if it is reused, which is cheap
+// as long as the retrieved credentials did not expire.
+func authMethodClient(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string, prov *esv1alpha1.AWSProvider, method esv1alpha1.AWSAuthMethod, assumeRoler awssess.STSProvider, clients *clientCache) (provider.SecretsClient, error) {
+	var sak, aks, source string
+	if method.SecretRef != nil {
+		var err error
+		sak, aks, err = secretRefCredentials(ctx, store, kube, namespace, method.SecretRef)
+		if err != nil {
+			return nil, err
+		}
+		source = credentialsSource(store, namespace, method.SecretRef)
+	}
+	secretsClient, err := cachedClient(prov, sak, aks, source, method.Name, assumeRoler, clients)
 	if err != nil {
 		return nil, err
 	}
-	sess, err := newSession(ctx, store, kube, namespace, assumeRoler)
+	if validator, ok := secretsClient.(provider.Validator); ok {
+		if err := validator.Validate(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return secretsClient, nil
+}
+
+// cachedClient returns the client for the provider and credentials from the cache
+// or creates a new one. Clients of previous credentials of the same source are evicted.
+func cachedClient(prov *esv1alpha1.AWSProvider, sak, aks, source, authMethod string, assumeRoler awssess.STSProvider, clients *clientCache) (provider.SecretsClient, error) {
+	key := newClientCacheKey(prov, sak, aks)
+	key.credentialsSource = source
+	key.authMethod = authMethod
+	if cached, ok := clients.get(key); ok {
+		return cached, nil
+	}
+	sess, err := createSession(prov, sak, aks, assumeRoler)
 	if err != nil {
 		return nil, fmt.Errorf(errUnableCreateSession, err)
 	}
+	var secretsClient provider.SecretsClient
 	switch prov.Service {
 	case esv1alpha1.AWSServiceSecretsManager:
-		return secretsmanager.New(sess)
+		var sm *secretsmanager.SecretsManager
+		sm, err = secretsmanager.New(sess)
+		if err == nil {
+			secretsClient = sm.WithAllowedKMSKeyIDs(prov.AllowedKMSKeyIDs).
+				WithReplicaFallback(prov.ReplicaFallback, prov.PrimaryRegion).
+				WithContentTypeFromTags(prov.ContentTypeFromTags).
+				WithIdentityVerifier(awssess.NewIdentityVerifier(sess, assumeRoler)).
+				WithAuthMethod(authMethod)
+		}
 	case esv1alpha1.AWSServiceParameterStore:
-		return parameterstore.New(sess)
+		var pm *parameterstore.ParameterStore
+		pm, err = parameterstore.New(sess)
+		if err == nil {
+			secretsClient = pm.WithIdentityVerifier(awssess.NewIdentityVerifier(sess, assumeRoler)).
+				WithAuthMethod(authMethod)
+		}
+	default:
+		return nil, fmt.Errorf(errUnknownProviderService, prov.Service)
 	}
-	return nil, fmt.Errorf(errUnknownProviderService, prov.Service)
+	if err != nil {
+		return nil, err
+	}
+	clients.add(key, secretsClient)
+	return secretsClient, nil
 }
 
 // newSession creates a new aws session based on a store
 // it looks up credentials at the provided secrets.
 func newSession(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string, assumeRoler awssess.STSProvider) (*session.Session, error) {
+	prov, err := resolveProvider(ctx, store, kube, namespace)
+	if err != nil {
+		return nil, err
+	}
+	sak, aks, err := sessionCredentials(ctx, store, kube, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return createSession(prov, sak, aks, assumeRoler)
+}
+
+// resolveProvider returns a copy of the AWS provider of the store
+// with the region read from the referenced ConfigMap, if any.
+func resolveProvider(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string) (*esv1alpha1.AWSProvider, error) {
 	prov, err := getAWSProvider(store)
 	if err != nil {
 		return nil, err
 	}
-	var sak, aks string
-	// use provided credentials via secret reference
-	if prov.Auth != nil {
-		log.V(1).Info("fetching secrets for authentication")
-		ke := client.ObjectKey{
-			Name:      prov.Auth.SecretRef.AccessKeyID.Name,
-			Namespace: namespace, // default to ExternalSecret namespace
-		}
-		// only ClusterStore is allowed to set namespace (and then it's required)
-		if store.GetObjectKind().GroupVersionKind().Kind == esv1alpha1.ClusterSecretStoreKind {
-			if prov.Auth.SecretRef.AccessKeyID.Namespace == nil {
-				return nil, fmt.Errorf(errInvalidClusterStoreMissingAKIDNamespace)
-			}
-			ke.Namespace = *prov.Auth.SecretRef.AccessKeyID.Namespace
-		}
-		akSecret := v1.Secret{}
-		err := kube.Get(ctx, ke, &akSecret)
-		if err != nil {
-			return nil, fmt.Errorf(errFetchAKIDSecret, err)
-		}
-		ke = client.ObjectKey{
-			Name:      prov.Auth.SecretRef.SecretAccessKey.Name,
-			Namespace: namespace, // default to ExternalSecret namespace
-		}
-		// only ClusterStore is allowed to set namespace (and then it's required)
-		if store.GetObjectKind().GroupVersionKind().Kind == esv1alpha1.ClusterSecretStoreKind {
-			if prov.Auth.SecretRef.SecretAccessKey.Namespace == nil {
-				return nil, fmt.Errorf(errInvalidClusterStoreMissingSAKNamespace)
-			}
-			ke.Namespace = *prov.Auth.SecretRef.SecretAccessKey.Namespace
+	if prov.RegionFrom == nil {
+		return prov, nil
+	}
+	if prov.Region != "" {
+		return nil, fmt.Errorf(errRegionAndRegionFrom)
+	}
+	ref := prov.RegionFrom.ConfigMapKeyRef
+	ke := client.ObjectKey{
+		Name:      ref.Name,
+		Namespace: namespace, // default to ExternalSecret namespace
+	}
+	// only ClusterStore is allowed to set namespace (and then it's required)
+	if store.GetObjectKind().GroupVersionKind().Kind == esv1alpha1.ClusterSecretStoreKind {
+		if ref.Namespace == nil {
+			return nil, fmt.Errorf(errInvalidClusterStoreMissingRegionNS)
 		}
-		sakSecret := v1.Secret{}
-		err = kube.Get(ctx, ke, &sakSecret)
-		if err != nil {
-			return nil, fmt.Errorf(errFetchSAKSecret, err)
+		ke.Namespace = *ref.Namespace
+	}
+	configMap := v1.ConfigMap{}
+	err = kube.Get(ctx, ke, &configMap)
+	if err != nil {
+		return nil, fmt.Errorf(errFetchRegionConfigMap, err)
+	}
+	region := configMap.Data[ref.Key]
+	if region == "" {
+		return nil, fmt.Errorf(errMissingRegionKey, ref.Key, ref.Name)
+	}
+	resolved := prov.DeepCopy()
+	resolved.Region = region
+	resolved.RegionFrom = nil
+	return resolved, nil
+}
+
+// sessionCredentials looks up the secret access key and the access key id
+// referenced by the store. Both are empty if the store does not reference
+// credentials, the default credential chain is used in that case.
+func sessionCredentials(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string) (sak, aks string, err error) {
+	prov, err := getAWSProvider(store)
+	if err != nil {
+		return "", "", err
+	}
+	// use provided credentials via secret reference
+	if prov.Auth == nil {
+		return "", "", nil
+	}
+	return secretRefCredentials(ctx, store, kube, namespace, &prov.Auth.SecretRef)
+}
+
+// secretRefCredentials reads the secret access key and the access key id from the referenced secrets.
+func secretRefCredentials(ctx context.Context, store esv1alpha1.GenericStore, kube client.Client, namespace string, ref *esv1alpha1.AWSAuthSecretRef) (sak, aks string, err error) {
+	log.V(1).Info("fetching secrets for authentication")
+	ke := client.ObjectKey{
+		Name:      ref.AccessKeyID.Name,
+		Namespace: namespace, // default to ExternalSecret namespace
+	}
+	// only ClusterStore is allowed to set namespace (and then it's required)
+	if store.GetObjectKind().GroupVersionKind().Kind == esv1alpha1.ClusterSecretStoreKind {
+		if ref.AccessKeyID.Namespace == nil {
+			return "", "", fmt.Errorf(errInvalidClusterStoreMissingAKIDNamespace)
 		}
-		sak = string(sakSecret.Data[prov.Auth.SecretRef.SecretAccessKey.Key])
-		aks = string(akSecret.Data[prov.Auth.SecretRef.AccessKeyID.Key])
-		if sak == "" {
-			return nil, fmt.Errorf(errMissingSAK)
+		ke.Namespace = *ref.AccessKeyID.Namespace
+	}
+	akSecret := v1.Secret{}
+	err = kube.Get(ctx, ke, &akSecret)
+	if err != nil {
+		return "", "", fmt.Errorf(errFetchAKIDSecret, err)
+	}
+	ke = client.ObjectKey{
+		Name:      ref.SecretAccessKey.Name,
+		Namespace: namespace, // default to ExternalSecret namespace
+	}
+	// only ClusterStore is allowed to set namespace (and then it's required)
+	if store.GetObjectKind().GroupVersionKind().Kind == esv1alpha1.ClusterSecretStoreKind {
+		if ref.SecretAccessKey.Namespace == nil {
+			return "", "", fmt.Errorf(errInvalidClusterStoreMissingSAKNamespace)
 		}
-		if aks == "" {
-			return nil, fmt.Errorf(errMissingAKID)
+		ke.Namespace = *ref.SecretAccessKey.Namespace
+	}
+	sakSecret := v1.Secret{}
+	err = kube.Get(ctx, ke, &sakSecret)
+	if err != nil {
+		return "", "", fmt.Errorf(errFetchSAKSecret, err)
+	}
+	sak = string(sakSecret.Data[ref.SecretAccessKey.Key])
+	aks = string(akSecret.Data[ref.AccessKeyID.Key])
+	if sak == "" {
+		return "", "", fmt.Errorf(errMissingSAK)
+	}
+	if aks == "" {
+		return "", "", fmt.Errorf(errMissingAKID)
+	}
+	return sak, aks, nil
+}
+
+// credentialsSource identifies the Secret keys of the secret reference
+// the way secretRefCredentials resolves them.
+func credentialsSource(store esv1alpha1.GenericStore, namespace string, ref *esv1alpha1.AWSAuthSecretRef) string {
+	keyOf := func(selector esmeta.SecretKeySelector) string {
+		ns := namespace
+		if store.GetObjectKind().GroupVersionKind().Kind == esv1alpha1.ClusterSecretStoreKind && selector.Namespace != nil {
+			ns = *selector.Namespace
 		}
+		return ns + "/" + selector.Name + "/" + selector.Key
 	}
+	return keyOf(ref.AccessKeyID) + "," + keyOf(ref.SecretAccessKey)
+}
+
+// createSession creates the session for the provider with the given credentials.
+func createSession(prov *esv1alpha1.AWSProvider, sak, aks string, assumeRoler awssess.STSProvider) (*session.Session, error) {
 	session, err := awssess.New(sak, aks, prov.Region, prov.Role, assumeRoler)
 	if err != nil {
 		return nil, err
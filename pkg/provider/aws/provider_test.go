@@ -16,22 +16,27 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	awssess "github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
 	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
 	"github.com/external-secrets/external-secrets/pkg/provider/aws/parameterstore"
 	"github.com/external-secrets/external-secrets/pkg/provider/aws/secretsmanager"
 	session "github.com/external-secrets/external-secrets/pkg/provider/aws/session"
@@ -110,6 +115,20 @@ func TestProvider(t *testing.T) {
 				},
 			},
 		},
+		{
+			test:   "replicaFallback without primaryRegion should return an error",
+			expErr: true,
+			store: &esv1alpha1.SecretStore{
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							Service:         esv1alpha1.AWSServiceSecretsManager,
+							ReplicaFallback: true,
+						},
+					},
+				},
+			},
+		},
 		{
 			test:   "newSession error should be returned",
 			expErr: true,
@@ -445,6 +464,144 @@ func TestNewSession(t *testing.T) {
 			},
 			expectErr: "invalid ClusterSecretStore: missing AWS AccessKeyID Namespace",
 		},
+		{
+			name:      "use the literal region",
+			namespace: "foo",
+			store: &esv1alpha1.SecretStore{
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							Region: "us-east-2",
+						},
+					},
+				},
+			},
+			env: map[string]string{
+				"AWS_ACCESS_KEY_ID":     "1111",
+				"AWS_SECRET_ACCESS_KEY": "2222",
+			},
+			expectProvider:    true,
+			expectedKeyID:     "1111",
+			expectedSecretKey: "2222",
+			expectedRegion:    "us-east-2",
+		},
+		{
+			name:      "read the region from a ConfigMap",
+			namespace: "foo",
+			store: &esv1alpha1.SecretStore{
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							RegionFrom: &esv1alpha1.AWSRegionSource{
+								ConfigMapKeyRef: esmeta.ConfigMapKeySelector{
+									Name: "aws-config",
+									Key:  "region",
+								},
+							},
+						},
+					},
+				},
+			},
+			configMaps: []v1.ConfigMap{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "aws-config",
+						Namespace: "foo",
+					},
+					Data: map[string]string{
+						"region": "eu-west-1",
+					},
+				},
+			},
+			env: map[string]string{
+				"AWS_ACCESS_KEY_ID":     "1111",
+				"AWS_SECRET_ACCESS_KEY": "2222",
+			},
+			expectProvider:    true,
+			expectedKeyID:     "1111",
+			expectedSecretKey: "2222",
+			expectedRegion:    "eu-west-1",
+		},
+		{
+			name:      "error out when the region ConfigMap does not exist",
+			namespace: "foo",
+			store: &esv1alpha1.SecretStore{
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							RegionFrom: &esv1alpha1.AWSRegionSource{
+								ConfigMapKeyRef: esmeta.ConfigMapKeySelector{
+									Name: "aws-config",
+									Key:  "region",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErr: `could not fetch region ConfigMap: configmaps "aws-config" not found`,
+		},
+		{
+			name:      "error out when the region key does not exist",
+			namespace: "foo",
+			store: &esv1alpha1.SecretStore{
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							RegionFrom: &esv1alpha1.AWSRegionSource{
+								ConfigMapKeyRef: esmeta.ConfigMapKeySelector{
+									Name: "aws-config",
+									Key:  "aws-region",
+								},
+							},
+						},
+					},
+				},
+			},
+			configMaps: []v1.ConfigMap{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "aws-config",
+						Namespace: "foo",
+					},
+					Data: map[string]string{
+						"region": "eu-west-1",
+					},
+				},
+			},
+			expectErr: `missing key "aws-region" in region ConfigMap "aws-config"`,
+		},
+		{
+			name:      "error out when region and regionFrom are combined",
+			namespace: "foo",
+			store: &esv1alpha1.SecretStore{
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							Region: "us-east-2",
+							RegionFrom: &esv1alpha1.AWSRegionSource{
+								ConfigMapKeyRef: esmeta.ConfigMapKeySelector{
+									Name: "aws-config",
+									Key:  "region",
+								},
+							},
+						},
+					},
+				},
+			},
+			configMaps: []v1.ConfigMap{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "aws-config",
+						Namespace: "foo",
+					},
+					Data: map[string]string{
+						"region": "eu-west-1",
+					},
+				},
+			},
+			expectErr: "region and regionFrom can not be combined",
+		},
 	}
 	for i := range rows {
 		row := rows[i]
@@ -464,6 +621,8 @@ type TestSessionRow struct {
 	expectErr         string
 	expectedKeyID     string
 	expectedSecretKey string
+	expectedRegion    string
+	configMaps        []v1.ConfigMap
 	env               map[string]string
 }
 
@@ -473,6 +632,10 @@ func testRow(t *testing.T, row TestSessionRow) {
 		err := kc.Create(context.Background(), &row.secrets[i])
 		assert.Nil(t, err)
 	}
+	for i := range row.configMaps {
+		err := kc.Create(context.Background(), &row.configMaps[i])
+		assert.Nil(t, err)
+	}
 	for k, v := range row.env {
 		os.Setenv(k, v)
 	}
@@ -496,6 +659,9 @@ func testRow(t *testing.T, row TestSessionRow) {
 	creds, _ := s.Config.Credentials.Get()
 	assert.Equal(t, creds.AccessKeyID, row.expectedKeyID)
 	assert.Equal(t, creds.SecretAccessKey, row.expectedSecretKey)
+	if row.expectedRegion != "" {
+		assert.Equal(t, row.expectedRegion, aws.StringValue(s.Config.Region))
+	}
 }
 
 func TestSMEnvCredentials(t *testing.T) {
@@ -616,3 +782,275 @@ func ErrorContains(out error, want string) bool {
 	}
 	return strings.Contains(out.Error(), want)
 }
+
+func TestNewClientReusesClients(t *testing.T) {
+	kc := clientfake.NewClientBuilder().WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "creds",
+			Namespace: "foo",
+		},
+		Data: map[string][]byte{
+			"one": []byte("1111"),
+			"two": []byte("2222"),
+		},
+	}).Build()
+	newStore := func(region string) *esv1alpha1.SecretStore {
+		return &esv1alpha1.SecretStore{
+			Spec: esv1alpha1.SecretStoreSpec{
+				Provider: &esv1alpha1.SecretStoreProvider{
+					AWS: &esv1alpha1.AWSProvider{
+						Service: esv1alpha1.AWSServiceSecretsManager,
+						Region:  region,
+						Auth: &esv1alpha1.AWSAuth{
+							SecretRef: esv1alpha1.AWSAuthSecretRef{
+								AccessKeyID: esmeta.SecretKeySelector{
+									Name: "creds",
+									Key:  "one",
+								},
+								SecretAccessKey: esmeta.SecretKeySelector{
+									Name: "creds",
+									Key:  "two",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	p := Provider{}
+
+	first, err := p.NewClient(context.Background(), newStore("eu-west-1"), kc, "foo")
+	assert.Nil(t, err)
+	second, err := p.NewClient(context.Background(), newStore("eu-west-1"), kc, "foo")
+	assert.Nil(t, err)
+	assert.Same(t, first, second, "identical configuration must reuse the client")
+
+	otherRegion, err := p.NewClient(context.Background(), newStore("us-east-1"), kc, "foo")
+	assert.Nil(t, err)
+	assert.NotSame(t, first, otherRegion, "a different region must create a new client")
+
+	var creds v1.Secret
+	assert.Nil(t, kc.Get(context.Background(), types.NamespacedName{Name: "creds", Namespace: "foo"}, &creds))
+	creds.Data["two"] = []byte("3333")
+	assert.Nil(t, kc.Update(context.Background(), &creds))
+	rotated, err := p.NewClient(context.Background(), newStore("eu-west-1"), kc, "foo")
+	assert.Nil(t, err)
+	assert.NotSame(t, first, rotated, "changed credentials must create a new client")
+	assert.Len(t, p.clients.clients, 1, "clients of the previous credentials must be evicted")
+}
+
+func TestClientCacheEvictsRotatedCredentials(t *testing.T) {
+	var cache clientCache
+	rotated := clientCacheKey{region: "eu-west-1", credentialsSource: "foo/creds/one,foo/creds/two", credentialsHash: "old"}
+	otherRegion := clientCacheKey{region: "us-east-1", credentialsSource: "foo/creds/one,foo/creds/two", credentialsHash: "old"}
+	otherSource := clientCacheKey{region: "eu-west-1", credentialsSource: "bar/creds/one,bar/creds/two", credentialsHash: "old"}
+	environment := clientCacheKey{region: "eu-west-1"}
+	for _, key := range []clientCacheKey{rotated, otherRegion, otherSource, environment} {
+		cache.add(key, &secretsmanager.SecretsManager{})
+	}
+
+	current := rotated
+	current.credentialsHash = "new"
+	cache.add(current, &secretsmanager.SecretsManager{})
+
+	_, ok := cache.get(rotated)
+	assert.False(t, ok, "the client of the previous credentials must be evicted")
+	_, ok = cache.get(otherRegion)
+	assert.False(t, ok, "all clients of the previous credentials must be evicted")
+	_, ok = cache.get(otherSource)
+	assert.True(t, ok, "clients of other credential sources must be kept")
+	_, ok = cache.get(environment)
+	assert.True(t, ok, "clients without credential source must be kept")
+	_, ok = cache.get(current)
+	assert.True(t, ok)
+}
+
+func TestClientCacheConcurrentAccess(t *testing.T) {
+	var cache clientCache
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := clientCacheKey{region: fmt.Sprintf("region-%d", i%3)}
+			if _, ok := cache.get(key); !ok {
+				cache.add(key, &secretsmanager.SecretsManager{})
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, cache.clients, 3)
+}
+
+func TestClientCacheLimit(t *testing.T) {
+	var cache clientCache
+	for i := 0; i < maxCachedClients; i++ {
+		cache.add(clientCacheKey{region: fmt.Sprintf("region-%d", i)}, &secretsmanager.SecretsManager{})
+	}
+	assert.Len(t, cache.clients, maxCachedClients)
+	cache.add(clientCacheKey{region: "one-too-many"}, &secretsmanager.SecretsManager{})
+	assert.Len(t, cache.clients, 1)
+}
+
+func TestNewClientAuthMethods(t *testing.T) {
+	kc := clientfake.NewClientBuilder().WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "creds",
+			Namespace: "foo",
+		},
+		Data: map[string][]byte{
+			"id":       []byte("1111"),
+			"key":      []byte("2222"),
+			"other-id": []byte("3333"),
+		},
+	}).Build()
+	secretRef := func(name, idKey string) *esv1alpha1.AWSAuthSecretRef {
+		return &esv1alpha1.AWSAuthSecretRef{
+			AccessKeyID: esmeta.SecretKeySelector{
+				Name: name,
+				Key:  idKey,
+			},
+			SecretAccessKey: esmeta.SecretKeySelector{
+				Name: name,
+				Key:  "key",
+			},
+		}
+	}
+	// the role can only be assumed with the access key id 1111
+	// and AWS does not accept the access key id 3333
+	stsProvider := func(sess *awssess.Session) stscreds.AssumeRoler {
+		creds, err := sess.Config.Credentials.Get()
+		assert.Nil(t, err)
+		return &fakesess.AssumeRoler{
+			GetCallerIdentityFunc: func(*sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+				if creds.AccessKeyID == "3333" {
+					return nil, awserr.New("InvalidClientTokenId", "the security token included in the request is invalid", nil)
+				}
+				return &sts.GetCallerIdentityOutput{}, nil
+			},
+			AssumeRoleFunc: func(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+				if creds.AccessKeyID != "1111" {
+					return nil, fmt.Errorf("access denied")
+				}
+				return &sts.AssumeRoleOutput{
+					Credentials: &sts.Credentials{
+						AccessKeyId:     aws.String("4444"),
+						SecretAccessKey: aws.String("5555"),
+						Expiration:      aws.Time(time.Now().Add(time.Hour)),
+						SessionToken:    aws.String("6666"),
+					},
+				}, nil
+			},
+		}
+	}
+
+	tbl := []struct {
+		test          string
+		service       esv1alpha1.AWSServiceType
+		staticKeys    bool
+		auth          *esv1alpha1.AWSAuth
+		methods       []esv1alpha1.AWSAuthMethod
+		expAuthMethod string
+		expErr        string
+	}{
+		{
+			test:       "first method has static keys that AWS does not accept",
+			service:    esv1alpha1.AWSServiceSecretsManager,
+			staticKeys: true,
+			methods: []esv1alpha1.AWSAuthMethod{
+				{Name: "invalid", SecretRef: secretRef("creds", "other-id")},
+				{Name: "static", SecretRef: secretRef("creds", "id")},
+			},
+			expAuthMethod: "static",
+		},
+		{
+			test:       "all static keys are not accepted",
+			service:    esv1alpha1.AWSServiceParameterStore,
+			staticKeys: true,
+			methods: []esv1alpha1.AWSAuthMethod{
+				{Name: "invalid", SecretRef: secretRef("creds", "other-id")},
+			},
+			expErr: "all authentication methods failed: invalid: unable to verify credentials",
+		},
+		{
+			test:    "first method references a missing secret",
+			service: esv1alpha1.AWSServiceSecretsManager,
+			methods: []esv1alpha1.AWSAuthMethod{
+				{Name: "missing", SecretRef: secretRef("missing", "id")},
+				{Name: "static", SecretRef: secretRef("creds", "id")},
+			},
+			expAuthMethod: "static",
+		},
+		{
+			test:    "first method can not assume the role",
+			service: esv1alpha1.AWSServiceParameterStore,
+			methods: []esv1alpha1.AWSAuthMethod{
+				{Name: "denied", SecretRef: secretRef("creds", "other-id")},
+				{Name: "allowed", SecretRef: secretRef("creds", "id")},
+			},
+			expAuthMethod: "allowed",
+		},
+		{
+			test:    "first method succeeds",
+			service: esv1alpha1.AWSServiceSecretsManager,
+			methods: []esv1alpha1.AWSAuthMethod{
+				{Name: "allowed", SecretRef: secretRef("creds", "id")},
+				{Name: "denied", SecretRef: secretRef("creds", "other-id")},
+			},
+			expAuthMethod: "allowed",
+		},
+		{
+			test:    "all methods fail",
+			service: esv1alpha1.AWSServiceSecretsManager,
+			methods: []esv1alpha1.AWSAuthMethod{
+				{Name: "missing", SecretRef: secretRef("missing", "id")},
+				{Name: "denied", SecretRef: secretRef("creds", "other-id")},
+			},
+			expErr: "all authentication methods failed: missing: could not fetch accessKeyID secret",
+		},
+		{
+			test:    "auth and authMethods",
+			service: esv1alpha1.AWSServiceSecretsManager,
+			auth: &esv1alpha1.AWSAuth{
+				SecretRef: *secretRef("creds", "id"),
+			},
+			methods: []esv1alpha1.AWSAuthMethod{
+				{Name: "static", SecretRef: secretRef("creds", "id")},
+			},
+			expErr: errAuthAndAuthMethods,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			role := "my-role"
+			if row.staticKeys {
+				role = ""
+			}
+			store := &esv1alpha1.SecretStore{
+				Spec: esv1alpha1.SecretStoreSpec{
+					Provider: &esv1alpha1.SecretStoreProvider{
+						AWS: &esv1alpha1.AWSProvider{
+							Service:     row.service,
+							Region:      "eu-west-1",
+							Role:        role,
+							Auth:        row.auth,
+							AuthMethods: row.methods,
+						},
+					},
+				},
+			}
+			secretsClient, err := newClient(context.Background(), store, kc, "foo", stsProvider, &clientCache{})
+			if !ErrorContains(err, row.expErr) {
+				t.Fatalf("expected error %q but found %v", row.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+			reporter, ok := secretsClient.(provider.AuthMethodReporter)
+			assert.True(t, ok)
+			assert.Equal(t, row.expAuthMethod, reporter.AuthMethod())
+		})
+	}
+}
@@ -22,7 +22,11 @@ import (
 
 // Client implements the aws secretsmanager interface.
 type Client struct {
-	valFn func(*awssm.GetSecretValueInput) (*awssm.GetSecretValueOutput, error)
+	valFn      func(*awssm.GetSecretValueInput) (*awssm.GetSecretValueOutput, error)
+	describeFn func(*awssm.DescribeSecretInput) (*awssm.DescribeSecretOutput, error)
+
+	// DescribeCalls counts the calls of DescribeSecret.
+	DescribeCalls int
 }
 
 func (sm *Client) GetSecretValue(in *awssm.GetSecretValueInput) (*awssm.GetSecretValueOutput, error) {
@@ -37,3 +41,35 @@ func (sm *Client) WithValue(in *awssm.GetSecretValueInput, val *awssm.GetSecretV
 		return val, err
 	}
 }
+
+// WithValues returns the given errors in order before it returns val.
+func (sm *Client) WithValues(in *awssm.GetSecretValueInput, val *awssm.GetSecretValueOutput, errs ...error) {
+	sm.valFn = func(paramIn *awssm.GetSecretValueInput) (*awssm.GetSecretValueOutput, error) {
+		if !cmp.Equal(paramIn, in) {
+			return nil, fmt.Errorf("unexpected test argument")
+		}
+		if len(errs) > 0 {
+			err := errs[0]
+			errs = errs[1:]
+			return nil, err
+		}
+		return val, nil
+	}
+}
+
+func (sm *Client) DescribeSecret(in *awssm.DescribeSecretInput) (*awssm.DescribeSecretOutput, error) {
+	sm.DescribeCalls++
+	if sm.describeFn == nil {
+		return &awssm.DescribeSecretOutput{}, nil
+	}
+	return sm.describeFn(in)
+}
+
+func (sm *Client) WithDescribe(in *awssm.DescribeSecretInput, val *awssm.DescribeSecretOutput, err error) {
+	sm.describeFn = func(paramIn *awssm.DescribeSecretInput) (*awssm.DescribeSecretOutput, error) {
+		if !cmp.Equal(paramIn, in) {
+			return nil, fmt.Errorf("unexpected test argument")
+		}
+		return val, err
+	}
+}
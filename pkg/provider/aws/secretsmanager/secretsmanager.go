@@ -14,49 +14,235 @@ limitations under the License.
 package secretsmanager
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"sync"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	awssm "github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/tidwall/gjson"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/mapformat"
+	"github.com/external-secrets/external-secrets/pkg/property"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+	awssess "github.com/external-secrets/external-secrets/pkg/provider/aws/session"
 )
 
 // SecretsManager is a provider for AWS SecretsManager.
 type SecretsManager struct {
 	client SMInterface
+	region string
+
+	// newRegionalClient creates clients for secrets that are referenced
+	// by an ARN that points to a different region.
+	newRegionalClient func(region string) SMInterface
+	regionalClients   map[string]SMInterface
+	mu                sync.Mutex
+
+	// refreshCredentials forces a refresh of the session credentials
+	// before a request that failed because of expired credentials is retried.
+	refreshCredentials func()
+
+	// credentials of the session, they are retrieved to validate the store.
+	credentials *credentials.Credentials
+
+	// identity verifies the credentials with AWS when the store is validated.
+	identity *awssess.IdentityVerifier
+
+	// allowedKMSKeyIDs are the KMS keys secrets must be encrypted with.
+	// Secrets are not checked if it is empty.
+	allowedKMSKeyIDs []string
+
+	// replicaFallback reads secrets that are not found from their primary and replica regions.
+	replicaFallback bool
+	// primaryRegion is the region the replication status of secrets is read from.
+	primaryRegion string
+
+	// contentTypeFromTags reads the content type of secrets from their ContentTypeTag.
+	contentTypeFromTags bool
+
+	// authMethod is the name of the authentication method of the store.
+	authMethod string
 }
 
 // SMInterface is a subset of the smiface api.
 // see: https://docs.aws.amazon.com/sdk-for-go/api/service/secretsmanager/secretsmanageriface/
 type SMInterface interface {
 	GetSecretValue(*awssm.GetSecretValueInput) (*awssm.GetSecretValueOutput, error)
+	DescribeSecret(*awssm.DescribeSecretInput) (*awssm.DescribeSecretOutput, error)
 }
 
+// errCodeAccessDenied is returned by AWS if the credentials are not allowed to perform a request.
+const errCodeAccessDenied = "AccessDeniedException"
+
+// ContentTypeTag is the tag of a secret that holds its content type.
+const ContentTypeTag = "external-secrets.io/content-type"
+
+// defaultKMSKeyAlias is the key used by SecretsManager if a secret has no KMS key configured.
+const defaultKMSKeyAlias = "alias/aws/secretsmanager"
+
+var versionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 var log = ctrl.Log.WithName("provider").WithName("aws").WithName("secretsmanager")
 
 // New creates a new SecretsManager client.
 func New(sess client.ConfigProvider) (*SecretsManager, error) {
-	return &SecretsManager{
-		client: awssm.New(sess),
-	}, nil
+	smClient := awssm.New(sess)
+	sm := &SecretsManager{
+		client: smClient,
+		region: aws.StringValue(smClient.Config.Region),
+		newRegionalClient: func(region string) SMInterface {
+			return awssm.New(sess, aws.NewConfig().WithRegion(region))
+		},
+	}
+	if smClient.Config.Credentials != nil {
+		sm.refreshCredentials = smClient.Config.Credentials.Expire
+		sm.credentials = smClient.Config.Credentials
+	}
+	return sm, nil
 }
 
-// GetSecret returns a single secret from the provider.
-func (sm *SecretsManager) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
-	ver := "AWSCURRENT"
-	if ref.Version != "" {
-		ver = ref.Version
+// WithAllowedKMSKeyIDs only allows to fetch secrets that are encrypted with one of the given KMS keys.
+func (sm *SecretsManager) WithAllowedKMSKeyIDs(keyIDs []string) *SecretsManager {
+	sm.allowedKMSKeyIDs = keyIDs
+	return sm
+}
+
+// WithReplicaFallback reads secrets that are not found in the region of the client
+// from primaryRegion and the replica regions in their replication status.
+func (sm *SecretsManager) WithReplicaFallback(enabled bool, primaryRegion string) *SecretsManager {
+	sm.replicaFallback = enabled
+	sm.primaryRegion = primaryRegion
+	return sm
+}
+
+// WithContentTypeFromTags reads the content type of secrets from their ContentTypeTag
+// if the remote ref does not set one.
+func (sm *SecretsManager) WithContentTypeFromTags(enabled bool) *SecretsManager {
+	sm.contentTypeFromTags = enabled
+	return sm
+}
+
+// WithIdentityVerifier sets the verifier of the credentials that is used by Validate.
+func (sm *SecretsManager) WithIdentityVerifier(identity *awssess.IdentityVerifier) *SecretsManager {
+	sm.identity = identity
+	return sm
+}
+
+// WithAuthMethod sets the name of the authentication method the client was created with.
+func (sm *SecretsManager) WithAuthMethod(name string) *SecretsManager {
+	sm.authMethod = name
+	return sm
+}
+
+// AuthMethod returns the name of the authentication method the client was created with.
+func (sm *SecretsManager) AuthMethod() string {
+	return sm.authMethod
+}
+
+// clientFor returns the client that is used to fetch the given key.
+// If the key is a full secret ARN the region of the ARN takes
+// precedence over the region configured in the store.
+func (sm *SecretsManager) clientFor(key string) SMInterface {
+	if !arn.IsARN(key) {
+		return sm.client
 	}
-	log.Info("fetching secret value", "key", ref.Key, "version", ver)
-	secretOut, err := sm.client.GetSecretValue(&awssm.GetSecretValueInput{
-		SecretId:     &ref.Key,
-		VersionStage: &ver,
+	secretARN, err := arn.Parse(key)
+	if err != nil || secretARN.Region == "" || secretARN.Region == sm.region || sm.newRegionalClient == nil {
+		return sm.client
+	}
+	log.V(1).Info("using region from secret ARN", "region", secretARN.Region, "account", secretARN.AccountID)
+	return sm.regionalClient(secretARN.Region)
+}
+
+// clientForRegion returns the client of the store for its region
+// and a regional client for other regions.
+func (sm *SecretsManager) clientForRegion(region string) SMInterface {
+	if region == sm.region {
+		return sm.client
+	}
+	return sm.regionalClient(region)
+}
+
+// regionalClient returns the client for another region. Clients are created once per region.
+func (sm *SecretsManager) regionalClient(region string) SMInterface {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.regionalClients == nil {
+		sm.regionalClients = make(map[string]SMInterface)
+	}
+	regionalClient, ok := sm.regionalClients[region]
+	if !ok {
+		regionalClient = sm.newRegionalClient(region)
+		sm.regionalClients[region] = regionalClient
+	}
+	return regionalClient
+}
+
+// Validate checks that credentials can be retrieved, e.g. that the role of the store
+// can be assumed, and that AWS accepts them, which also detects invalid static keys.
+// Credentials are cached until they expire and are verified once, so AWS is not
+// contacted on every validation.
+func (sm *SecretsManager) Validate(ctx context.Context) error {
+	if sm.credentials == nil {
+		return nil
+	}
+	if _, err := sm.credentials.GetWithContext(ctx); err != nil {
+		return fmt.Errorf("unable to retrieve credentials: %w", awssess.WrapRequestError(err))
+	}
+	if err := sm.identity.Verify(ctx); err != nil {
+		return fmt.Errorf("unable to verify credentials: %w", err)
+	}
+	return nil
+}
+
+// secretDescription describes a secret at most once per request, so the KMS key
+// and content type checks share a single DescribeSecret call.
+type secretDescription struct {
+	sm   *SecretsManager
+	key  string
+	done bool
+	out  *awssm.DescribeSecretOutput
+	err  error
+}
+
+func (sm *SecretsManager) describe(key string) *secretDescription {
+	return &secretDescription{sm: sm, key: key}
+}
+
+func (d *secretDescription) get() (*awssm.DescribeSecretOutput, error) {
+	if d.done {
+		return d.out, d.err
+	}
+	d.done = true
+	d.err = awssess.RefreshOnExpiredCredentials(d.sm.refreshCredentials, func() error {
+		var err error
+		d.out, err = d.sm.clientFor(d.key).DescribeSecret(&awssm.DescribeSecretInput{
+			SecretId: &d.key,
+		})
+		return err
 	})
+	return d.out, d.err
+}
+
+// GetSecret returns a single secret from the provider.
+func (sm *SecretsManager) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+	return sm.getSecret(ref, sm.describe(ref.Key))
+}
+
+func (sm *SecretsManager) getSecret(ref esv1alpha1.ExternalSecretDataRemoteRef, desc *secretDescription) ([]byte, error) {
+	secretOut, err := sm.getSecretValue(ref, desc)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +255,10 @@ func (sm *SecretsManager) GetSecret(ctx context.Context, ref esv1alpha1.External
 		}
 		return nil, fmt.Errorf("invalid secret received. no secret string nor binary for key: %s", ref.Key)
 	}
+	contentType := sm.contentType(ref, desc)
+	if contentType != "" && contentType != esv1alpha1.ContentTypeJSON {
+		return nil, fmt.Errorf("unable to extract property %s from secret %s with content type %s", ref.Property, ref.Key, contentType)
+	}
 	var payload string
 	if secretOut.SecretString != nil {
 		payload = *secretOut.SecretString
@@ -76,28 +266,289 @@ func (sm *SecretsManager) GetSecret(ctx context.Context, ref esv1alpha1.External
 	if secretOut.SecretBinary != nil {
 		payload = string(secretOut.SecretBinary)
 	}
-	val := gjson.Get(payload, ref.Property)
-	if !val.Exists() {
-		return nil, fmt.Errorf("key %s does not exist in secret %s", ref.Property, ref.Key)
+	if contentType == esv1alpha1.ContentTypeJSON && !gjson.Valid(payload) {
+		return nil, fmt.Errorf("secret %s with content type %s is not valid JSON", ref.Key, contentType)
+	}
+	val, err := property.Get(ref.PropertyEngine, payload, ref.Property)
+	if errors.Is(err, property.ErrNotFound) {
+		return nil, fmt.Errorf("%w: key %s does not exist in secret %s", provider.ErrPropertyNotFound, ref.Property, ref.Key)
+	}
+	if errors.Is(err, property.ErrInvalidJSON) {
+		return nil, fmt.Errorf("secret %s is not valid JSON", ref.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract property %s from secret %s: %w", ref.Property, ref.Key, err)
+	}
+	return []byte(val), nil
+}
+
+// getSecretValue fetches the value of ref after the KMS key of the secret was verified.
+func (sm *SecretsManager) getSecretValue(ref esv1alpha1.ExternalSecretDataRemoteRef, desc *secretDescription) (*awssm.GetSecretValueOutput, error) {
+	err := sm.verifyKMSKey(ref, desc)
+	if err != nil {
+		return nil, err
+	}
+	input := secretValueInput(ref)
+	var secretOut *awssm.GetSecretValueOutput
+	err = awssess.RefreshOnExpiredCredentials(sm.refreshCredentials, func() error {
+		var err error
+		secretOut, err = sm.clientFor(ref.Key).GetSecretValue(input)
+		return err
+	})
+	if sm.replicaFallback && isNotFound(err) {
+		return sm.getReplicaSecretValue(ref, input, err)
+	}
+	return secretOut, err
+}
+
+// getReplicaSecretValue reads the value from the primary region of the secret
+// and its replica regions that are in sync. The replication status is described
+// in the primary region, the secret is missing in the requested region.
+// notFoundErr is returned if no region has the value.
+func (sm *SecretsManager) getReplicaSecretValue(ref esv1alpha1.ExternalSecretDataRemoteRef, input *awssm.GetSecretValueInput, notFoundErr error) (*awssm.GetSecretValueOutput, error) {
+	requestedRegion := sm.region
+	if secretARN, err := arn.Parse(ref.Key); err == nil && secretARN.Region != "" {
+		requestedRegion = secretARN.Region
+	}
+	if sm.newRegionalClient == nil || sm.primaryRegion == "" || sm.primaryRegion == requestedRegion {
+		return nil, notFoundErr
+	}
+	var out *awssm.DescribeSecretOutput
+	err := awssess.RefreshOnExpiredCredentials(sm.refreshCredentials, func() error {
+		var err error
+		out, err = sm.clientForRegion(sm.primaryRegion).DescribeSecret(&awssm.DescribeSecretInput{
+			SecretId: aws.String(replicaSecretID(ref.Key, sm.primaryRegion)),
+		})
+		return err
+	})
+	if err != nil {
+		log.V(1).Info("unable to describe secret in primary region", "key", ref.Key, "region", sm.primaryRegion, "error", err.Error())
+		return nil, notFoundErr
+	}
+	regions := []string{sm.primaryRegion}
+	for _, replica := range out.ReplicationStatus {
+		region := aws.StringValue(replica.Region)
+		if region == "" || region == requestedRegion || aws.StringValue(replica.Status) != awssm.StatusTypeInSync {
+			continue
+		}
+		regions = append(regions, region)
 	}
-	return []byte(val.String()), nil
+	for _, region := range regions {
+		replicaInput := *input
+		replicaInput.SecretId = aws.String(replicaSecretID(ref.Key, region))
+		var secretOut *awssm.GetSecretValueOutput
+		err := awssess.RefreshOnExpiredCredentials(sm.refreshCredentials, func() error {
+			var err error
+			secretOut, err = sm.clientForRegion(region).GetSecretValue(&replicaInput)
+			return err
+		})
+		if err == nil {
+			log.Info("read secret from replica region", "key", ref.Key, "region", region)
+			return secretOut, nil
+		}
+		log.V(1).Info("unable to read secret from replica region", "key", ref.Key, "region", region, "error", err.Error())
+	}
+	return nil, notFoundErr
+}
+
+// replicaSecretID returns the id of the secret in the replica region.
+// Names are the same in all regions, ARNs contain the region.
+func replicaSecretID(key, region string) string {
+	if !arn.IsARN(key) {
+		return key
+	}
+	secretARN, err := arn.Parse(key)
+	if err != nil {
+		return key
+	}
+	secretARN.Region = region
+	return secretARN.String()
+}
+
+func isNotFound(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == awssm.ErrCodeResourceNotFoundException
+}
+
+// secretValueInput selects the version of ref by its id or by its staging label.
+// AWSCURRENT is used if no version is set.
+func secretValueInput(ref esv1alpha1.ExternalSecretDataRemoteRef) *awssm.GetSecretValueInput {
+	ver := "AWSCURRENT"
+	if ref.Version != "" {
+		ver = ref.Version
+	}
+	log.Info("fetching secret value", "key", ref.Key, "version", ver)
+	if isVersionID(ver) {
+		return &awssm.GetSecretValueInput{
+			SecretId:  &ref.Key,
+			VersionId: &ver,
+		}
+	}
+	return &awssm.GetSecretValueInput{
+		SecretId:     &ref.Key,
+		VersionStage: &ver,
+	}
+}
+
+// IsPinnedVersion returns true if ref selects a version by its id.
+// Staging labels like AWSCURRENT move to other versions when the secret changes.
+func (sm *SecretsManager) IsPinnedVersion(ref esv1alpha1.ExternalSecretDataRemoteRef) bool {
+	return isVersionID(ref.Version)
+}
+
+// isVersionID tells version ids apart from staging labels.
+// Version ids are UUIDs, staging labels are chosen by the user.
+func isVersionID(version string) bool {
+	return versionIDPattern.MatchString(version)
 }
 
 // GetSecretMap returns multiple k/v pairs from the provider.
 func (sm *SecretsManager) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
 	log.Info("fetching secret map", "key", ref.Key)
-	data, err := sm.GetSecret(ctx, ref)
+	if !mapformat.IsJSON(ref.MapFormat) {
+		data, err := sm.GetSecret(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		secretData, err := mapformat.Parse(ref.MapFormat, data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse secret %s: %w", ref.Key, err)
+		}
+		return secretData, nil
+	}
+	desc := sm.describe(ref.Key)
+	contentType := sm.contentType(ref, desc)
+	if contentType != "" && contentType != esv1alpha1.ContentTypeJSON {
+		return nil, fmt.Errorf("unable to use secret %s with content type %s as map", ref.Key, contentType)
+	}
+	data, err := sm.getSecret(ref, desc)
 	if err != nil {
 		return nil, err
 	}
-	kv := make(map[string]string)
-	err = json.Unmarshal(data, &kv)
+	if ref.UnwrapJSONString {
+		data, err = mapformat.UnwrapJSONString(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse secret %s: %w", ref.Key, err)
+		}
+	}
+	if ref.MapKeyField != "" {
+		return mapByKeyField(data, ref)
+	}
+	secretData, err := mapformat.ParseJSON(data)
 	if err != nil {
 		return nil, fmt.Errorf("unable to unmarshal secret %s: %w", ref.Key, err)
 	}
-	secretData := make(map[string][]byte)
-	for k, v := range kv {
-		secretData[k] = []byte(v)
+	return secretData, nil
+}
+
+// mapByKeyField turns a JSON array of objects into a map. Every object
+// is stored under the value of its ref.MapKeyField property.
+func mapByKeyField(data []byte, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	var items []json.RawMessage
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal secret %s as array: %w", ref.Key, err)
+	}
+	secretData := make(map[string][]byte, len(items))
+	for i, item := range items {
+		val := gjson.GetBytes(item, ref.MapKeyField)
+		if !val.Exists() {
+			return nil, fmt.Errorf("key field %s does not exist in item %d of secret %s", ref.MapKeyField, i, ref.Key)
+		}
+		k := val.String()
+		if _, ok := secretData[k]; ok {
+			return nil, fmt.Errorf("duplicate value %q of key field %s in secret %s", k, ref.MapKeyField, ref.Key)
+		}
+		buf := bytes.NewBuffer(nil)
+		err = json.Compact(buf, item)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize item %d of secret %s: %w", i, ref.Key, err)
+		}
+		secretData[k] = buf.Bytes()
 	}
 	return secretData, nil
 }
+
+// Exists checks whether the secret exists using DescribeSecret, which does not return the value.
+// Secrets that are scheduled for deletion are reported as not existing.
+func (sm *SecretsManager) Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	var out *awssm.DescribeSecretOutput
+	err := awssess.RefreshOnExpiredCredentials(sm.refreshCredentials, func() error {
+		var err error
+		out, err = sm.clientFor(ref.Key).DescribeSecret(&awssm.DescribeSecretInput{
+			SecretId: &ref.Key,
+		})
+		return err
+	})
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case awssm.ErrCodeResourceNotFoundException:
+			return false, nil
+		case errCodeAccessDenied:
+			return false, fmt.Errorf("%w: secret %s: %s", provider.ErrAccessDenied, ref.Key, err)
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return out.DeletedDate == nil, nil
+}
+
+// verifyKMSKey returns an error wrapping provider.ErrDisallowedKMSKey if the secret
+// is not encrypted with one of the allowed KMS keys. It does nothing if no keys are allowed explicitly.
+func (sm *SecretsManager) verifyKMSKey(ref esv1alpha1.ExternalSecretDataRemoteRef, desc *secretDescription) error {
+	if len(sm.allowedKMSKeyIDs) == 0 {
+		return nil
+	}
+	out, err := desc.get()
+	if err != nil {
+		return fmt.Errorf("unable to verify KMS key of secret %s: %w", ref.Key, err)
+	}
+	keyID := aws.StringValue(out.KmsKeyId)
+	if keyID == "" {
+		keyID = defaultKMSKeyAlias
+	}
+	for _, allowed := range sm.allowedKMSKeyIDs {
+		if normalizeKMSKeyID(allowed) == normalizeKMSKeyID(keyID) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: secret %s is encrypted with %s", provider.ErrDisallowedKMSKey, ref.Key, keyID)
+}
+
+// normalizeKMSKeyID returns the resource part of a KMS key or alias ARN,
+// e.g. key/1234abcd-... or alias/my-key. Plain key ids are prefixed with key/.
+func normalizeKMSKeyID(keyID string) string {
+	if arn.IsARN(keyID) {
+		parsed, err := arn.Parse(keyID)
+		if err == nil {
+			return parsed.Resource
+		}
+	}
+	if strings.HasPrefix(keyID, "alias/") || strings.HasPrefix(keyID, "key/") {
+		return keyID
+	}
+	return "key/" + keyID
+}
+
+// contentType returns the content type of the secret. The content type
+// of the remote ref takes precedence over the ContentTypeTag of the secret,
+// which is only read if enabled. An empty content type means it is unknown,
+// also if the secret can not be described.
+func (sm *SecretsManager) contentType(ref esv1alpha1.ExternalSecretDataRemoteRef, desc *secretDescription) esv1alpha1.ContentType {
+	if ref.ContentType != "" || !sm.contentTypeFromTags {
+		return ref.ContentType
+	}
+	out, err := desc.get()
+	if err != nil {
+		log.V(1).Info("unable to read content type", "key", ref.Key, "error", err.Error())
+		return ""
+	}
+	for _, tag := range out.Tags {
+		if aws.StringValue(tag.Key) == ContentTypeTag {
+			return esv1alpha1.ContentType(aws.StringValue(tag.Value))
+		}
+	}
+	return ""
+}
@@ -15,18 +15,29 @@ package secretsmanager
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
 	awssm "github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
 	fakesm "github.com/external-secrets/external-secrets/pkg/provider/aws/secretsmanager/fake"
 	sess "github.com/external-secrets/external-secrets/pkg/provider/aws/session"
+	fakesess "github.com/external-secrets/external-secrets/pkg/provider/aws/session/fake"
 )
 
 func TestConstructor(t *testing.T) {
@@ -35,6 +46,489 @@ func TestConstructor(t *testing.T) {
 	c, err := New(s)
 	assert.Nil(t, err)
 	assert.NotNil(t, c.client)
+	assert.Equal(t, "foo", c.region)
+}
+
+// secrets referenced by ARN must be fetched from the region of the ARN.
+func TestGetSecretRegionFromARN(t *testing.T) {
+	const arnKey = "arn:aws:secretsmanager:us-west-2:123456789012:secret:foo-AbCdEf"
+	defaultFake := &fakesm.Client{}
+	regionalFake := &fakesm.Client{}
+	var requestedRegions []string
+	p := &SecretsManager{
+		client: defaultFake,
+		region: "eu-central-1",
+		newRegionalClient: func(region string) SMInterface {
+			requestedRegions = append(requestedRegions, region)
+			return regionalFake
+		},
+	}
+	defaultFake.WithValue(&awssm.GetSecretValueInput{
+		SecretId:     aws.String("foo"),
+		VersionStage: aws.String("AWSCURRENT"),
+	}, &awssm.GetSecretValueOutput{
+		SecretString: aws.String("default"),
+	}, nil)
+	regionalFake.WithValue(&awssm.GetSecretValueInput{
+		SecretId:     aws.String(arnKey),
+		VersionStage: aws.String("AWSCURRENT"),
+	}, &awssm.GetSecretValueOutput{
+		SecretString: aws.String("regional"),
+	}, nil)
+
+	out, err := p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: arnKey})
+	assert.Nil(t, err)
+	assert.Equal(t, "regional", string(out))
+
+	// regional clients are reused
+	out, err = p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: arnKey})
+	assert.Nil(t, err)
+	assert.Equal(t, "regional", string(out))
+	assert.Equal(t, []string{"us-west-2"}, requestedRegions)
+
+	// a secret name uses the store region
+	out, err = p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+	assert.Nil(t, err)
+	assert.Equal(t, "default", string(out))
+
+	// an ARN in the store region uses the default client
+	sameRegionARN := "arn:aws:secretsmanager:eu-central-1:123456789012:secret:foo-AbCdEf"
+	defaultFake.WithValue(&awssm.GetSecretValueInput{
+		SecretId:     aws.String(sameRegionARN),
+		VersionStage: aws.String("AWSCURRENT"),
+	}, &awssm.GetSecretValueOutput{
+		SecretString: aws.String("default"),
+	}, nil)
+	out, err = p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: sameRegionARN})
+	assert.Nil(t, err)
+	assert.Equal(t, "default", string(out))
+	assert.Equal(t, []string{"us-west-2"}, requestedRegions)
+}
+
+// secrets that are not found in the store region are read from their primary region
+// or their replicas if replicaFallback is enabled. AWS does not describe the secret
+// in the store region either, so the replication status is read from the primary region.
+func TestGetSecretReplicaFallback(t *testing.T) {
+	const (
+		storeARN   = "arn:aws:secretsmanager:eu-central-1:123456789012:secret:foo-AbCdEf"
+		primaryARN = "arn:aws:secretsmanager:us-east-1:123456789012:secret:foo-AbCdEf"
+		replicaARN = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:foo-AbCdEf"
+	)
+	errNotFound := awserr.New(awssm.ErrCodeResourceNotFoundException, "secrets manager can't find the specified secret", nil)
+	errBoom := errors.New("boom")
+	replication := &awssm.DescribeSecretOutput{
+		ReplicationStatus: []*awssm.ReplicationStatusType{
+			{Region: aws.String("ap-south-1"), Status: aws.String(awssm.StatusTypeFailed)},
+			{Region: aws.String("eu-central-1"), Status: aws.String(awssm.StatusTypeInSync)},
+			{Region: aws.String("eu-west-1"), Status: aws.String(awssm.StatusTypeInSync)},
+		},
+	}
+	tbl := []struct {
+		test            string
+		key             string
+		disabled        bool
+		primaryRegion   string
+		storeErr        error
+		describeErr     error
+		primaryKey      string
+		primaryErr      error
+		replicaKey      string
+		replicaErr      error
+		expectedSecret  string
+		expectedErr     error
+		expectedRegions []string
+	}{
+		{
+			test:            "secret is read from the primary region",
+			key:             "foo",
+			storeErr:        errNotFound,
+			primaryKey:      "foo",
+			expectedSecret:  "primary",
+			expectedRegions: []string{"us-east-1"},
+		},
+		{
+			test:            "secret is read from the replica in sync",
+			key:             "foo",
+			storeErr:        errNotFound,
+			primaryKey:      "foo",
+			primaryErr:      errNotFound,
+			replicaKey:      "foo",
+			expectedSecret:  "replica",
+			expectedRegions: []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			test:            "the region of an ARN is replaced",
+			key:             storeARN,
+			storeErr:        errNotFound,
+			primaryKey:      primaryARN,
+			primaryErr:      errNotFound,
+			replicaKey:      replicaARN,
+			expectedSecret:  "replica",
+			expectedRegions: []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			test:            "not found is returned if no region has the secret",
+			key:             "foo",
+			storeErr:        errNotFound,
+			primaryKey:      "foo",
+			primaryErr:      errNotFound,
+			replicaKey:      "foo",
+			replicaErr:      errNotFound,
+			expectedErr:     errNotFound,
+			expectedRegions: []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			test:            "not found is returned if the primary region does not describe the secret",
+			key:             "foo",
+			storeErr:        errNotFound,
+			describeErr:     errNotFound,
+			primaryKey:      "foo",
+			expectedErr:     errNotFound,
+			expectedRegions: []string{"us-east-1"},
+		},
+		{
+			test:          "not found is returned if the store is in the primary region",
+			key:           "foo",
+			primaryRegion: "eu-central-1",
+			storeErr:      errNotFound,
+			expectedErr:   errNotFound,
+		},
+		{
+			test:        "regions are not read if the fallback is disabled",
+			key:         "foo",
+			disabled:    true,
+			storeErr:    errNotFound,
+			expectedErr: errNotFound,
+		},
+		{
+			test:        "regions are not read on other errors",
+			key:         "foo",
+			storeErr:    errBoom,
+			expectedErr: errBoom,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			store := &fakesm.Client{}
+			store.WithValue(&awssm.GetSecretValueInput{
+				SecretId:     aws.String(row.key),
+				VersionStage: aws.String("AWSCURRENT"),
+			}, nil, row.storeErr)
+			// a secret that is missing in a region is not described there either
+			store.WithDescribe(&awssm.DescribeSecretInput{SecretId: aws.String(row.key)}, nil, errNotFound)
+			primary := &fakesm.Client{}
+			primary.WithValue(&awssm.GetSecretValueInput{
+				SecretId:     aws.String(row.primaryKey),
+				VersionStage: aws.String("AWSCURRENT"),
+			}, &awssm.GetSecretValueOutput{SecretString: aws.String("primary")}, row.primaryErr)
+			describeOutput := replication
+			if row.describeErr != nil {
+				describeOutput = nil
+			}
+			primary.WithDescribe(&awssm.DescribeSecretInput{SecretId: aws.String(row.primaryKey)}, describeOutput, row.describeErr)
+			replica := &fakesm.Client{}
+			replica.WithValue(&awssm.GetSecretValueInput{
+				SecretId:     aws.String(row.replicaKey),
+				VersionStage: aws.String("AWSCURRENT"),
+			}, &awssm.GetSecretValueOutput{SecretString: aws.String("replica")}, row.replicaErr)
+			regional := map[string]*fakesm.Client{"us-east-1": primary, "eu-west-1": replica}
+			primaryRegion := row.primaryRegion
+			if primaryRegion == "" {
+				primaryRegion = "us-east-1"
+			}
+			var requestedRegions []string
+			p := (&SecretsManager{
+				client: store,
+				region: "eu-central-1",
+				newRegionalClient: func(region string) SMInterface {
+					requestedRegions = append(requestedRegions, region)
+					return regional[region]
+				},
+			}).WithReplicaFallback(!row.disabled, primaryRegion)
+			out, err := p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: row.key})
+			if row.expectedErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, errors.Is(err, row.expectedErr), "unexpected error: %v", err)
+			}
+			assert.Equal(t, row.expectedSecret, string(out))
+			assert.Equal(t, row.expectedRegions, requestedRegions)
+		})
+	}
+}
+
+// requests that fail because of expired credentials are retried once after a refresh.
+func TestGetSecretExpiredCredentials(t *testing.T) {
+	errExpired := awserr.New("ExpiredTokenException", "the security token included in the request is expired", nil)
+	in := &awssm.GetSecretValueInput{
+		SecretId:     aws.String("foo"),
+		VersionStage: aws.String("AWSCURRENT"),
+	}
+	out := &awssm.GetSecretValueOutput{
+		SecretString: aws.String("bar"),
+	}
+	tbl := []struct {
+		test              string
+		errs              []error
+		expectedRefreshes int
+		expectedErr       error
+	}{
+		{
+			test:              "refresh and retry once",
+			errs:              []error{errExpired},
+			expectedRefreshes: 1,
+		},
+		{
+			test:              "fail if the credentials expire again",
+			errs:              []error{errExpired, errExpired},
+			expectedRefreshes: 1,
+			expectedErr:       errExpired,
+		},
+		{
+			test:        "do not retry other errors",
+			errs:        []error{fmt.Errorf("boom")},
+			expectedErr: fmt.Errorf("boom"),
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			fake := &fakesm.Client{}
+			fake.WithValues(in, out, row.errs...)
+			refreshes := 0
+			p := &SecretsManager{
+				client: fake,
+				refreshCredentials: func() {
+					refreshes++
+				},
+			}
+			val, err := p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+			assert.Equal(t, row.expectedErr, err)
+			assert.Equal(t, row.expectedRefreshes, refreshes)
+			if row.expectedErr == nil {
+				assert.Equal(t, "bar", string(val))
+			}
+		})
+	}
+}
+
+// failed requests must carry the request id, so it shows up in the status of the ExternalSecret.
+func TestGetSecretRequestID(t *testing.T) {
+	const requestID = "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"
+	errFailure := awserr.NewRequestFailure(awserr.New(awssm.ErrCodeResourceNotFoundException, "secrets manager can't find the specified secret", nil), 400, requestID)
+	fake := &fakesm.Client{}
+	fake.WithValue(&awssm.GetSecretValueInput{
+		SecretId:     aws.String("foo"),
+		VersionStage: aws.String("AWSCURRENT"),
+	}, nil, errFailure)
+	p := &SecretsManager{client: fake}
+	_, err := p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+	assert.Contains(t, err.Error(), "request id: "+requestID)
+	var requestErr *sess.RequestError
+	if assert.True(t, errors.As(err, &requestErr)) {
+		assert.Equal(t, requestID, requestErr.RequestID)
+		assert.Equal(t, awssm.ErrCodeResourceNotFoundException, requestErr.Code)
+		assert.Equal(t, 400, requestErr.StatusCode)
+	}
+}
+
+// the error codes of Secrets Manager are reported as errors of the provider package,
+// so the ExternalSecret gets a condition reason for them.
+func TestGetSecretErrorKind(t *testing.T) {
+	tbl := []struct {
+		code         string
+		expectedKind error
+	}{
+		{code: awssm.ErrCodeResourceNotFoundException, expectedKind: provider.ErrSecretNotFound},
+		{code: awssm.ErrCodeDecryptionFailure, expectedKind: provider.ErrDecryptionFailed},
+		{code: "AccessDeniedException", expectedKind: provider.ErrAccessDenied},
+		{code: "ThrottlingException", expectedKind: provider.ErrThrottled},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.code, func(t *testing.T) {
+			fake := &fakesm.Client{}
+			fake.WithValue(&awssm.GetSecretValueInput{
+				SecretId:     aws.String("foo"),
+				VersionStage: aws.String("AWSCURRENT"),
+			}, nil, awserr.NewRequestFailure(awserr.New(row.code, "some api err", nil), 400, "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"))
+			p := &SecretsManager{client: fake}
+			_, err := p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+			assert.True(t, errors.Is(err, row.expectedKind), "unexpected error: %v", err)
+		})
+	}
+}
+
+// the store is valid if credentials of the session can be retrieved.
+func TestValidate(t *testing.T) {
+	for _, row := range []struct {
+		name        string
+		credentials *credentials.Credentials
+		identityErr error
+		expectError string
+	}{
+		{
+			name: "no credentials",
+		},
+		{
+			name:        "valid credentials",
+			credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		},
+		{
+			name:        "credentials can not be retrieved",
+			credentials: credentials.NewStaticCredentials("", "", ""),
+			expectError: "unable to retrieve credentials",
+		},
+		{
+			name:        "credentials are not accepted",
+			credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+			identityErr: awserr.New("InvalidClientTokenId", "the security token included in the request is invalid", nil),
+			expectError: "unable to verify credentials",
+		},
+	} {
+		t.Run(row.name, func(t *testing.T) {
+			s, err := sess.New("AKID", "SECRET", "eu-west-1", "", nil)
+			assert.Nil(t, err)
+			identity := sess.NewIdentityVerifier(s, func(*session.Session) stscreds.AssumeRoler {
+				return &fakesess.AssumeRoler{
+					GetCallerIdentityFunc: func(*sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+						return nil, row.identityErr
+					},
+				}
+			})
+			p := &SecretsManager{client: &fakesm.Client{}, credentials: row.credentials, identity: identity}
+			err = p.Validate(context.Background())
+			if !ErrorContains(err, row.expectError) {
+				t.Errorf("unexpected error: %v, expected: '%s'", err, row.expectError)
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	errBoom := errors.New("boom")
+	errAccessDenied := awserr.New("AccessDeniedException", "not authorized to perform secretsmanager:DescribeSecret", nil)
+	errNotFound := awserr.New(awssm.ErrCodeResourceNotFoundException, "secrets manager can't find the specified secret", nil)
+	tbl := []struct {
+		test           string
+		apiOutput      *awssm.DescribeSecretOutput
+		apiErr         error
+		expectedExists bool
+		expectedErr    error
+	}{
+		{
+			test:           "secret exists",
+			apiOutput:      &awssm.DescribeSecretOutput{Name: aws.String("foo")},
+			expectedExists: true,
+		},
+		{
+			test:   "secret does not exist",
+			apiErr: errNotFound,
+		},
+		{
+			test:      "secret is scheduled for deletion",
+			apiOutput: &awssm.DescribeSecretOutput{Name: aws.String("foo"), DeletedDate: aws.Time(time.Now())},
+		},
+		{
+			test:        "access denied",
+			apiErr:      errAccessDenied,
+			expectedErr: provider.ErrAccessDenied,
+		},
+		{
+			test:        "other errors are returned",
+			apiErr:      errBoom,
+			expectedErr: errBoom,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			fake := &fakesm.Client{}
+			fake.WithDescribe(&awssm.DescribeSecretInput{SecretId: aws.String("foo")}, row.apiOutput, row.apiErr)
+			p := &SecretsManager{client: fake}
+			exists, err := p.Exists(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+			assert.Equal(t, row.expectedExists, exists)
+			if row.expectedErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, errors.Is(err, row.expectedErr), "unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetSecretKMSKey(t *testing.T) {
+	const keyARN = "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	errBoom := errors.New("boom")
+	tbl := []struct {
+		test           string
+		allowedKeys    []string
+		describeOutput *awssm.DescribeSecretOutput
+		describeErr    error
+		expectedErr    error
+	}{
+		{
+			test:           "key id matches the key ARN of the secret",
+			allowedKeys:    []string{"alias/other", "1234abcd-12ab-34cd-56ef-1234567890ab"},
+			describeOutput: &awssm.DescribeSecretOutput{KmsKeyId: aws.String(keyARN)},
+		},
+		{
+			test:           "secret without key uses the default key",
+			allowedKeys:    []string{"alias/aws/secretsmanager"},
+			describeOutput: &awssm.DescribeSecretOutput{},
+		},
+		{
+			test:           "disallowed key",
+			allowedKeys:    []string{"alias/approved"},
+			describeOutput: &awssm.DescribeSecretOutput{KmsKeyId: aws.String(keyARN)},
+			expectedErr:    provider.ErrDisallowedKMSKey,
+		},
+		{
+			test:        "describe errors are returned",
+			allowedKeys: []string{"alias/approved"},
+			describeErr: errBoom,
+			expectedErr: errBoom,
+		},
+		{
+			test:        "check is skipped without allowed keys",
+			describeErr: errBoom,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			fake := &fakesm.Client{}
+			fake.WithDescribe(&awssm.DescribeSecretInput{SecretId: aws.String("foo")}, row.describeOutput, row.describeErr)
+			fake.WithValue(&awssm.GetSecretValueInput{
+				SecretId:     aws.String("foo"),
+				VersionStage: aws.String("AWSCURRENT"),
+			}, &awssm.GetSecretValueOutput{SecretString: aws.String("bar")}, nil)
+			p := (&SecretsManager{client: fake}).WithAllowedKMSKeyIDs(row.allowedKeys)
+			val, err := p.GetSecret(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+			if row.expectedErr != nil {
+				assert.True(t, errors.Is(err, row.expectedErr), "unexpected error: %v", err)
+				assert.Nil(t, val)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, "bar", string(val))
+		})
+	}
+}
+
+func TestIsPinnedVersion(t *testing.T) {
+	p := &SecretsManager{}
+	for version, expected := range map[string]bool{
+		"":                                     false,
+		"AWSCURRENT":                           false,
+		"AWSPREVIOUS":                          false,
+		"my-label":                             false,
+		"7b0b0ae6-5d4c-4bbd-b5a4-6c1b6d8b8a43": true,
+		"7B0B0AE6-5D4C-4BBD-B5A4-6C1B6D8B8A43": true,
+	} {
+		assert.Equal(t, expected, p.IsPinnedVersion(esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo", Version: version}), version)
+	}
 }
 
 // test the sm<->aws interface
@@ -86,6 +580,77 @@ func TestGetSecret(t *testing.T) {
 			expectError:    "",
 			expectedSecret: "bang",
 		},
+		{
+			// good case: extract property with jsonpath
+			apiInput: &awssm.GetSecretValueInput{
+				SecretId:     aws.String("/baz"),
+				VersionStage: aws.String("AWSCURRENT"),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:            "/baz",
+				Property:       "$.name.first",
+				PropertyEngine: esv1alpha1.PropertyEngineJSONPath,
+			},
+			apiOutput: &awssm.GetSecretValueOutput{
+				SecretString: aws.String(`{"name": {"first": "Tom"}}`),
+			},
+			apiErr:         nil,
+			expectError:    "",
+			expectedSecret: "Tom",
+		},
+		{
+			// good case: extract property with jq
+			apiInput: &awssm.GetSecretValueInput{
+				SecretId:     aws.String("/baz"),
+				VersionStage: aws.String("AWSCURRENT"),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:            "/baz",
+				Property:       ".name.first",
+				PropertyEngine: esv1alpha1.PropertyEngineJQ,
+			},
+			apiOutput: &awssm.GetSecretValueOutput{
+				SecretString: aws.String(`{"name": {"first": "Tom"}}`),
+			},
+			apiErr:         nil,
+			expectError:    "",
+			expectedSecret: "Tom",
+		},
+		{
+			// bad case: jq property on invalid json
+			apiInput: &awssm.GetSecretValueInput{
+				SecretId:     aws.String("/baz"),
+				VersionStage: aws.String("AWSCURRENT"),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:            "/baz",
+				Property:       ".name",
+				PropertyEngine: esv1alpha1.PropertyEngineJQ,
+			},
+			apiOutput: &awssm.GetSecretValueOutput{
+				SecretString: aws.String(`------`),
+			},
+			apiErr:         nil,
+			expectError:    "secret /baz is not valid JSON",
+			expectedSecret: "",
+		},
+		{
+			// good case: version ids are passed as VersionId instead of VersionStage
+			apiInput: &awssm.GetSecretValueInput{
+				SecretId:  aws.String("/baz"),
+				VersionId: aws.String("7b0b0ae6-5d4c-4bbd-b5a4-6c1b6d8b8a43"),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:     "/baz",
+				Version: "7b0b0ae6-5d4c-4bbd-b5a4-6c1b6d8b8a43",
+			},
+			apiOutput: &awssm.GetSecretValueOutput{
+				SecretString: aws.String("RRRRR"),
+			},
+			apiErr:         nil,
+			expectError:    "",
+			expectedSecret: "RRRRR",
+		},
 		{
 			// bad case: missing property
 			apiInput: &awssm.GetSecretValueInput{
@@ -117,7 +682,7 @@ func TestGetSecret(t *testing.T) {
 				SecretString: aws.String(`------`),
 			},
 			apiErr:         nil,
-			expectError:    "key INVALPROP does not exist in secret",
+			expectError:    "secret /baz is not valid JSON",
 			expectedSecret: "",
 		},
 		{
@@ -279,6 +844,43 @@ func TestGetSecretMap(t *testing.T) {
 			apiErr:       nil,
 			expectError:  "unable to unmarshal secret",
 		},
+		{
+			// good case: properties with a section
+			apiInput: &awssm.GetSecretValueInput{
+				SecretId:     aws.String("/baz"),
+				VersionStage: aws.String("AWSCURRENT"),
+			},
+			apiOutput: &awssm.GetSecretValueOutput{
+				SecretString: aws.String("foo=bar\n[db]\nuser=admin\n"),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:       "/baz",
+				MapFormat: esv1alpha1.MapFormatProperties,
+			},
+			expectedData: map[string]string{
+				"foo":     "bar",
+				"db.user": "admin",
+			},
+			apiErr:      nil,
+			expectError: "",
+		},
+		{
+			// bad case: malformed properties
+			apiInput: &awssm.GetSecretValueInput{
+				SecretId:     aws.String("/baz"),
+				VersionStage: aws.String("AWSCURRENT"),
+			},
+			apiOutput: &awssm.GetSecretValueOutput{
+				SecretString: aws.String("foo=bar\nnot a property\n"),
+			},
+			rr: esv1alpha1.ExternalSecretDataRemoteRef{
+				Key:       "/baz",
+				MapFormat: esv1alpha1.MapFormatProperties,
+			},
+			expectedData: map[string]string{},
+			apiErr:       nil,
+			expectError:  "unable to parse secret /baz: unable to parse properties: line 2",
+		},
 	} {
 		fake.WithValue(row.apiInput, row.apiOutput, row.apiErr)
 		out, err := p.GetSecretMap(context.Background(), row.rr)
@@ -291,6 +893,338 @@ func TestGetSecretMap(t *testing.T) {
 	}
 }
 
+// large JSON secrets are parsed on every refresh.
+func BenchmarkGetSecretMap(b *testing.B) {
+	kv := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		kv[fmt.Sprintf("key-%d", i)] = strings.Repeat(fmt.Sprintf("value-%d\n", i), 30)
+	}
+	payload, err := json.Marshal(kv)
+	if err != nil {
+		b.Fatal(err)
+	}
+	fake := &fakesm.Client{}
+	fake.WithValue(&awssm.GetSecretValueInput{
+		SecretId:     aws.String("/baz"),
+		VersionStage: aws.String("AWSCURRENT"),
+	}, &awssm.GetSecretValueOutput{
+		SecretString: aws.String(string(payload)),
+	}, nil)
+	p := &SecretsManager{client: fake}
+	rr := esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz"}
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.GetSecretMap(context.Background(), rr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// a JSON array of objects is turned into a map by the key field.
+func TestGetSecretMapByKeyField(t *testing.T) {
+	const users = `[
+		{"username": "alice", "password": "a"},
+		{"username": "bob", "password": "b", "roles": ["admin"]}
+	]`
+	for i, row := range []struct {
+		secretString string
+		keyField     string
+		expectError  string
+		expectedData map[string][]byte
+	}{
+		{
+			// good case: objects are keyed by the field
+			secretString: users,
+			keyField:     "username",
+			expectedData: map[string][]byte{
+				"alice": []byte(`{"username":"alice","password":"a"}`),
+				"bob":   []byte(`{"username":"bob","password":"b","roles":["admin"]}`),
+			},
+		},
+		{
+			// bad case: duplicate key field values
+			secretString: `[{"username": "alice", "password": "a"}, {"username": "alice", "password": "b"}]`,
+			keyField:     "username",
+			expectError:  `duplicate value "alice" of key field username in secret /baz`,
+		},
+		{
+			// bad case: key field is missing
+			secretString: users,
+			keyField:     "email",
+			expectError:  "key field email does not exist in item 0 of secret /baz",
+		},
+		{
+			// bad case: no array
+			secretString: `{"username": "alice"}`,
+			keyField:     "username",
+			expectError:  "unable to unmarshal secret /baz as array",
+		},
+	} {
+		fake := &fakesm.Client{}
+		p := &SecretsManager{
+			client: fake,
+		}
+		fake.WithValue(&awssm.GetSecretValueInput{
+			SecretId:     aws.String("/baz"),
+			VersionStage: aws.String("AWSCURRENT"),
+		}, &awssm.GetSecretValueOutput{
+			SecretString: aws.String(row.secretString),
+		}, nil)
+		out, err := p.GetSecretMap(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{
+			Key:         "/baz",
+			MapKeyField: row.keyField,
+		})
+		if !ErrorContains(err, row.expectError) {
+			t.Errorf("[%d] unexpected error: %v, expected: '%s'", i, err, row.expectError)
+		}
+		if !cmp.Equal(out, row.expectedData, cmpopts.EquateEmpty()) {
+			t.Errorf("[%d] unexpected secret data: expected %#v, got %#v", i, row.expectedData, out)
+		}
+	}
+}
+
+// double encoded JSON is only unwrapped if the option is set.
+func TestGetSecretMapUnwrapJSONString(t *testing.T) {
+	for i, row := range []struct {
+		secretString string
+		unwrap       bool
+		expectError  string
+		expectedData map[string][]byte
+	}{
+		{
+			// good case: double encoded value is unwrapped
+			secretString: `"{\"foo\":\"bar\"}"`,
+			unwrap:       true,
+			expectedData: map[string][]byte{
+				"foo": []byte("bar"),
+			},
+		},
+		{
+			// good case: plain objects are not affected by the option
+			secretString: `{"foo":"bar"}`,
+			unwrap:       true,
+			expectedData: map[string][]byte{
+				"foo": []byte("bar"),
+			},
+		},
+		{
+			// bad case: double encoded value is left as it is without the option
+			secretString: `"{\"foo\":\"bar\"}"`,
+			expectError:  "unable to unmarshal secret /baz",
+		},
+		{
+			// bad case: only one level is unwrapped
+			secretString: `"\"{\\\"foo\\\":\\\"bar\\\"}\""`,
+			unwrap:       true,
+			expectError:  "unable to parse secret /baz: unable to unwrap JSON string: value is encoded as JSON string more than once",
+		},
+	} {
+		fake := &fakesm.Client{}
+		p := &SecretsManager{
+			client: fake,
+		}
+		fake.WithValue(&awssm.GetSecretValueInput{
+			SecretId:     aws.String("/baz"),
+			VersionStage: aws.String("AWSCURRENT"),
+		}, &awssm.GetSecretValueOutput{
+			SecretString: aws.String(row.secretString),
+		}, nil)
+		out, err := p.GetSecretMap(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{
+			Key:              "/baz",
+			UnwrapJSONString: row.unwrap,
+		})
+		if !ErrorContains(err, row.expectError) {
+			t.Errorf("[%d] unexpected error: %v, expected: '%s'", i, err, row.expectError)
+		}
+		if !cmp.Equal(out, row.expectedData, cmpopts.EquateEmpty()) {
+			t.Errorf("[%d] unexpected secret data: expected %#v, got %#v", i, row.expectedData, out)
+		}
+	}
+}
+
+// the content type decides whether a value is parsed as JSON or used as it is.
+func TestGetSecretContentType(t *testing.T) {
+	const jsonLooking = `{"foo":"bar"}`
+	describeInput := &awssm.DescribeSecretInput{
+		SecretId: aws.String("/baz"),
+	}
+	taggedAs := func(contentType string) *awssm.DescribeSecretOutput {
+		return &awssm.DescribeSecretOutput{
+			Tags: []*awssm.Tag{
+				{Key: aws.String("team"), Value: aws.String("a")},
+				{Key: aws.String(ContentTypeTag), Value: aws.String(contentType)},
+			},
+		}
+	}
+	for i, row := range []struct {
+		describeOutput *awssm.DescribeSecretOutput
+		describeErr    error
+		fromTags       bool
+		secretString   string
+		rr             esv1alpha1.ExternalSecretDataRemoteRef
+		useMap         bool
+		expectError    string
+		expectedSecret string
+		expectedData   map[string][]byte
+		expectDescribe int
+	}{
+		{
+			// text/plain is stored as it is even if it looks like JSON
+			fromTags:       true,
+			describeOutput: taggedAs("text/plain"),
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz"},
+			expectedSecret: jsonLooking,
+		},
+		{
+			// properties can not be extracted from text/plain
+			fromTags:       true,
+			describeOutput: taggedAs("text/plain"),
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz", Property: "foo"},
+			expectError:    "unable to extract property foo from secret /baz with content type text/plain",
+			expectDescribe: 1,
+		},
+		{
+			// text/plain can not be used as map
+			fromTags:       true,
+			describeOutput: taggedAs("text/plain"),
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz"},
+			useMap:         true,
+			expectError:    "unable to use secret /baz with content type text/plain as map",
+			expectDescribe: 1,
+		},
+		{
+			// the content type of the remote ref overrides the tag
+			describeOutput: taggedAs("text/plain"),
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz", Property: "foo", ContentType: esv1alpha1.ContentTypeJSON},
+			expectedSecret: "bar",
+		},
+		{
+			// the tag is not looked up if the remote ref has a content type
+			describeErr:  fmt.Errorf("access denied"),
+			secretString: jsonLooking,
+			rr:           esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz", ContentType: esv1alpha1.ContentTypeText},
+			useMap:       true,
+			expectError:  "unable to use secret /baz with content type text/plain as map",
+		},
+		{
+			// application/json must be valid JSON
+			fromTags:       true,
+			describeOutput: taggedAs("application/json"),
+			secretString:   `------`,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz", Property: "foo"},
+			expectError:    "secret /baz with content type application/json is not valid JSON",
+			expectDescribe: 1,
+		},
+		{
+			// application/json can be used as map
+			fromTags:       true,
+			describeOutput: taggedAs("application/json"),
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz"},
+			useMap:         true,
+			expectedData:   map[string][]byte{"foo": []byte("bar")},
+			expectDescribe: 1,
+		},
+		{
+			// a secret that can not be described has an unknown content type
+			describeErr:    fmt.Errorf("access denied"),
+			fromTags:       true,
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz", Property: "foo"},
+			expectedSecret: "bar",
+			expectDescribe: 1,
+		},
+		{
+			// tags are not read unless enabled
+			describeOutput: taggedAs("text/plain"),
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz", Property: "foo"},
+			expectedSecret: "bar",
+		},
+		{
+			// tags are not read unless enabled
+			describeOutput: taggedAs("text/plain"),
+			secretString:   jsonLooking,
+			rr:             esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz"},
+			useMap:         true,
+			expectedData:   map[string][]byte{"foo": []byte("bar")},
+		},
+	} {
+		fake := &fakesm.Client{}
+		p := &SecretsManager{
+			client:              fake,
+			contentTypeFromTags: row.fromTags,
+		}
+		fake.WithValue(&awssm.GetSecretValueInput{
+			SecretId:     aws.String("/baz"),
+			VersionStage: aws.String("AWSCURRENT"),
+		}, &awssm.GetSecretValueOutput{
+			SecretString: aws.String(row.secretString),
+		}, nil)
+		fake.WithDescribe(describeInput, row.describeOutput, row.describeErr)
+		if row.useMap {
+			out, err := p.GetSecretMap(context.Background(), row.rr)
+			if !ErrorContains(err, row.expectError) {
+				t.Errorf("[%d] unexpected error: %v, expected: '%s'", i, err, row.expectError)
+			}
+			if !cmp.Equal(out, row.expectedData, cmpopts.EquateEmpty()) {
+				t.Errorf("[%d] unexpected secret data: expected %#v, got %#v", i, row.expectedData, out)
+			}
+		} else {
+			out, err := p.GetSecret(context.Background(), row.rr)
+			if !ErrorContains(err, row.expectError) {
+				t.Errorf("[%d] unexpected error: %v, expected: '%s'", i, err, row.expectError)
+			}
+			if string(out) != row.expectedSecret {
+				t.Errorf("[%d] unexpected secret: expected %s, got %s", i, row.expectedSecret, string(out))
+			}
+		}
+		if row.expectDescribe != fake.DescribeCalls {
+			t.Errorf("[%d] unexpected describe calls: expected %d, got %d", i, row.expectDescribe, fake.DescribeCalls)
+		}
+	}
+}
+
+func TestGetSecretSharesDescribe(t *testing.T) {
+	fake := &fakesm.Client{}
+	p := &SecretsManager{
+		client:              fake,
+		contentTypeFromTags: true,
+		allowedKMSKeyIDs:    []string{"alias/allowed"},
+	}
+	fake.WithValue(&awssm.GetSecretValueInput{
+		SecretId:     aws.String("/baz"),
+		VersionStage: aws.String("AWSCURRENT"),
+	}, &awssm.GetSecretValueOutput{
+		SecretString: aws.String(`{"foo":"bar"}`),
+	}, nil)
+	fake.WithDescribe(&awssm.DescribeSecretInput{
+		SecretId: aws.String("/baz"),
+	}, &awssm.DescribeSecretOutput{
+		KmsKeyId: aws.String("alias/allowed"),
+		Tags: []*awssm.Tag{
+			{Key: aws.String(ContentTypeTag), Value: aws.String("application/json")},
+		},
+	}, nil)
+	out, err := p.GetSecretMap(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "/baz"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cmp.Equal(out, map[string][]byte{"foo": []byte("bar")}) {
+		t.Errorf("unexpected secret data: %#v", out)
+	}
+	if fake.DescribeCalls != 1 {
+		t.Errorf("expected a single describe call, got %d", fake.DescribeCalls)
+	}
+}
+
 func ErrorContains(out error, want string) bool {
 	if out == nil {
 		return want == ""
@@ -13,12 +13,25 @@ limitations under the License.
 */
 package fake
 
-import "github.com/aws/aws-sdk-go/service/sts"
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
 
 type AssumeRoler struct {
-	AssumeRoleFunc func(*sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error)
+	AssumeRoleFunc        func(*sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error)
+	GetCallerIdentityFunc func(*sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error)
 }
 
 func (f *AssumeRoler) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
 	return f.AssumeRoleFunc(input)
 }
+
+// GetCallerIdentityWithContext succeeds unless GetCallerIdentityFunc is set.
+func (f *AssumeRoler) GetCallerIdentityWithContext(ctx aws.Context, input *sts.GetCallerIdentityInput, opts ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	if f.GetCallerIdentityFunc == nil {
+		return &sts.GetCallerIdentityOutput{}, nil
+	}
+	return f.GetCallerIdentityFunc(input)
+}
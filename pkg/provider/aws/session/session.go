@@ -15,15 +15,22 @@ limitations under the License.
 package session
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	awssess "github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/external-secrets/external-secrets/pkg/provider"
 )
 
 // Config contains configuration to create a new AWS provider.
@@ -33,6 +40,10 @@ type Config struct {
 	APIRetries int
 }
 
+// CredentialsExpiryWindow is the time before their expiry at which
+// assumed role credentials are refreshed.
+const CredentialsExpiryWindow = 5 * time.Minute
+
 var log = ctrl.Log.WithName("provider").WithName("aws")
 
 // New creates a new aws session based on the supported input methods.
@@ -58,7 +69,9 @@ func New(sak, aks, region, role string, stsprovider STSProvider) (*awssess.Sessi
 	if role != "" {
 		log.V(1).Info("assuming role", "role", role)
 		stsclient := stsprovider(sess)
-		sess.Config.WithCredentials(stscreds.NewCredentialsWithClient(stsclient, role))
+		sess.Config.WithCredentials(stscreds.NewCredentialsWithClient(stsclient, role, func(p *stscreds.AssumeRoleProvider) {
+			p.ExpiryWindow = CredentialsExpiryWindow
+		}))
 	}
 	sess.Handlers.Build.PushBack(request.WithAppendUserAgent("external-secrets"))
 	return sess, nil
@@ -69,3 +82,142 @@ type STSProvider func(*awssess.Session) stscreds.AssumeRoler
 func DefaultSTSProvider(sess *awssess.Session) stscreds.AssumeRoler {
 	return sts.New(sess)
 }
+
+// CallerIdentityGetter is implemented by STS clients that can verify credentials.
+type CallerIdentityGetter interface {
+	GetCallerIdentityWithContext(aws.Context, *sts.GetCallerIdentityInput, ...request.Option) (*sts.GetCallerIdentityOutput, error)
+}
+
+// IdentityVerifier verifies the credentials of a session with sts:GetCallerIdentity.
+// Retrieving static credentials never contacts AWS, so invalid keys are only
+// detected this way. A successful verification is remembered, so AWS is
+// contacted once per session.
+type IdentityVerifier struct {
+	sess        *awssess.Session
+	stsProvider STSProvider
+
+	mu       sync.Mutex
+	verified bool
+}
+
+// NewIdentityVerifier returns a verifier for the credentials of the session.
+// The STS client is created with stsProvider once the credentials are verified.
+func NewIdentityVerifier(sess *awssess.Session, stsProvider STSProvider) *IdentityVerifier {
+	return &IdentityVerifier{sess: sess, stsProvider: stsProvider}
+}
+
+// Verify calls sts:GetCallerIdentity with the credentials of the session,
+// unless it succeeded before. STS clients that can not get the caller
+// identity are not verified.
+func (v *IdentityVerifier) Verify(ctx context.Context) error {
+	if v == nil {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.verified {
+		return nil
+	}
+	if getter, ok := v.stsProvider(v.sess).(CallerIdentityGetter); ok {
+		if _, err := getter.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+			return WrapRequestError(err)
+		}
+	}
+	v.verified = true
+	return nil
+}
+
+// RefreshOnExpiredCredentials calls fn. If it fails because the credentials
+// expired anyway, refresh is called and fn is retried once.
+// Failed requests are returned as RequestError.
+func RefreshOnExpiredCredentials(refresh func(), fn func() error) error {
+	err := fn()
+	if refresh == nil || !request.IsErrorExpiredCreds(err) {
+		return WrapRequestError(err)
+	}
+	log.Info("credentials expired, refreshing", "error", err.Error())
+	refresh()
+	return WrapRequestError(fn())
+}
+
+// errorCodes maps the error codes of AWS to the errors providers wrap,
+// so the ExternalSecret controller can tell the causes apart.
+var errorCodes = map[string]error{
+	"AccessDeniedException":     provider.ErrAccessDenied,
+	"InvalidClientTokenId":      provider.ErrAccessDenied,
+	"SignatureDoesNotMatch":     provider.ErrAccessDenied,
+	"ResourceNotFoundException": provider.ErrSecretNotFound,
+	"ParameterNotFound":         provider.ErrSecretNotFound,
+	"DecryptionFailure":         provider.ErrDecryptionFailed,
+	"ThrottlingException":       provider.ErrThrottled,
+}
+
+// RequestError is returned if AWS rejected a request. It carries the request id
+// AWS support asks for when a failed request is investigated.
+type RequestError struct {
+	Code       string
+	Message    string
+	StatusCode int
+	RequestID  string
+
+	err  error
+	kind error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s: %s (status code: %d, request id: %s)", e.Code, e.Message, e.StatusCode, e.RequestID)
+}
+
+// Unwrap returns the error of the SDK, so it can still be inspected with errors.As.
+func (e *RequestError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is the provider error of the error code.
+func (e *RequestError) Is(target error) bool {
+	return e.kind != nil && e.kind == target
+}
+
+// codeError marks an error of the SDK without request with the provider error of its code.
+type codeError struct {
+	err  error
+	kind error
+}
+
+func (e *codeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codeError) Unwrap() error {
+	return e.err
+}
+
+func (e *codeError) Is(target error) bool {
+	return e.kind == target
+}
+
+// WrapRequestError returns a RequestError if err is a failed request of the SDK.
+// Errors with a known error code also match the provider error of the code with errors.Is,
+// e.g. provider.ErrAccessDenied for AccessDeniedException. Other errors are returned unchanged.
+func WrapRequestError(err error) error {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return err
+	}
+	kind := errorCodes[awsErr.Code()]
+	var failure awserr.RequestFailure
+	if errors.As(err, &failure) {
+		return &RequestError{
+			Code:       failure.Code(),
+			Message:    failure.Message(),
+			StatusCode: failure.StatusCode(),
+			RequestID:  failure.RequestID(),
+			err:        err,
+			kind:       kind,
+		}
+	}
+	if kind == nil {
+		return err
+	}
+	return &codeError{err: err, kind: kind}
+}
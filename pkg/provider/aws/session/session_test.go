@@ -15,15 +15,20 @@ limitations under the License.
 package session
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/external-secrets/external-secrets/pkg/provider"
 	fakesess "github.com/external-secrets/external-secrets/pkg/provider/aws/session/fake"
 )
 
@@ -89,3 +94,208 @@ func TestSession(t *testing.T) {
 		})
 	}
 }
+
+func TestAssumeRoleExpiryWindow(t *testing.T) {
+	expiration := time.Now().Add(time.Hour)
+	sess, err := New("", "", "xxxxx", "zzzzz", func(*session.Session) stscreds.AssumeRoler {
+		return &fakesess.AssumeRoler{
+			AssumeRoleFunc: func(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+				return &sts.AssumeRoleOutput{
+					Credentials: &sts.Credentials{
+						SecretAccessKey: aws.String("3333"),
+						AccessKeyId:     aws.String("4444"),
+						Expiration:      aws.Time(expiration),
+						SessionToken:    aws.String("6666"),
+					},
+				}, nil
+			},
+		}
+	})
+	assert.Nil(t, err)
+	_, err = sess.Config.Credentials.Get()
+	assert.Nil(t, err)
+	expiresAt, err := sess.Config.Credentials.ExpiresAt()
+	assert.Nil(t, err)
+	assert.True(t, expiresAt.Equal(expiration.Add(-CredentialsExpiryWindow)))
+}
+
+func TestIdentityVerifier(t *testing.T) {
+	sess, err := New("1111", "2222", "xxxxx", "", DefaultSTSProvider)
+	assert.Nil(t, err)
+	calls := 0
+	identityErr := awserr.New("InvalidClientTokenId", "the security token included in the request is invalid", nil)
+	verifier := NewIdentityVerifier(sess, func(*session.Session) stscreds.AssumeRoler {
+		return &fakesess.AssumeRoler{
+			GetCallerIdentityFunc: func(*sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+				calls++
+				return nil, identityErr
+			},
+		}
+	})
+	// failed verifications are retried
+	for i := 0; i < 2; i++ {
+		err = verifier.Verify(context.Background())
+		assert.True(t, errors.Is(err, provider.ErrAccessDenied), "unexpected error: %v", err)
+	}
+	assert.Equal(t, 2, calls)
+
+	// successful verifications are remembered
+	identityErr = nil
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, verifier.Verify(context.Background()))
+	}
+	assert.Equal(t, 3, calls)
+
+	// STS clients that can not get the caller identity are not verified
+	verifier = NewIdentityVerifier(sess, func(*session.Session) stscreds.AssumeRoler {
+		return stscreds.AssumeRoler(nil)
+	})
+	assert.Nil(t, verifier.Verify(context.Background()))
+
+	var nilVerifier *IdentityVerifier
+	assert.Nil(t, nilVerifier.Verify(context.Background()))
+}
+
+func TestRefreshOnExpiredCredentials(t *testing.T) {
+	errExpired := awserr.New("ExpiredTokenException", "the security token included in the request is expired", nil)
+	errOther := errors.New("boom")
+	tbl := []struct {
+		test              string
+		errs              []error
+		expectedCalls     int
+		expectedRefreshes int
+		expectedErr       error
+	}{
+		{
+			test:          "no error",
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			test:          "other error is not retried",
+			errs:          []error{errOther},
+			expectedCalls: 1,
+			expectedErr:   errOther,
+		},
+		{
+			test:              "expired credentials are refreshed once",
+			errs:              []error{errExpired, nil},
+			expectedCalls:     2,
+			expectedRefreshes: 1,
+		},
+		{
+			test:              "second expiry is returned",
+			errs:              []error{errExpired, errExpired},
+			expectedCalls:     2,
+			expectedRefreshes: 1,
+			expectedErr:       errExpired,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			calls, refreshes := 0, 0
+			err := RefreshOnExpiredCredentials(func() {
+				refreshes++
+			}, func() error {
+				err := row.errs[calls]
+				calls++
+				return err
+			})
+			assert.Equal(t, row.expectedErr, err)
+			assert.Equal(t, row.expectedCalls, calls)
+			assert.Equal(t, row.expectedRefreshes, refreshes)
+		})
+	}
+}
+
+func TestWrapRequestError(t *testing.T) {
+	errFailure := awserr.NewRequestFailure(awserr.New("AccessDeniedException", "not authorized", nil), 400, "7c6e4a52-1f0b-4e5f-9d0a-3e1b2c3d4e5f")
+	errOther := errors.New("oh no")
+	errAPI := awserr.New("InternalServiceError", "some api err", nil)
+	tbl := []struct {
+		test        string
+		err         error
+		expectedErr error
+	}{
+		{
+			test: "nil is returned unchanged",
+		},
+		{
+			test:        "other errors are returned unchanged",
+			err:         errOther,
+			expectedErr: errOther,
+		},
+		{
+			test:        "api errors without request are returned unchanged",
+			err:         errAPI,
+			expectedErr: errAPI,
+		},
+		{
+			test: "failed requests carry the request id",
+			err:  fmt.Errorf("unable to get secret: %w", errFailure),
+			expectedErr: &RequestError{
+				Code:       "AccessDeniedException",
+				Message:    "not authorized",
+				StatusCode: 400,
+				RequestID:  "7c6e4a52-1f0b-4e5f-9d0a-3e1b2c3d4e5f",
+				err:        fmt.Errorf("unable to get secret: %w", errFailure),
+				kind:       provider.ErrAccessDenied,
+			},
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			assert.Equal(t, row.expectedErr, WrapRequestError(row.err))
+		})
+	}
+}
+
+// the error codes of AWS match the errors of the provider package.
+func TestWrapRequestErrorKind(t *testing.T) {
+	tbl := []struct {
+		code         string
+		expectedKind error
+	}{
+		{code: "AccessDeniedException", expectedKind: provider.ErrAccessDenied},
+		{code: "ResourceNotFoundException", expectedKind: provider.ErrSecretNotFound},
+		{code: "ParameterNotFound", expectedKind: provider.ErrSecretNotFound},
+		{code: "DecryptionFailure", expectedKind: provider.ErrDecryptionFailed},
+		{code: "ThrottlingException", expectedKind: provider.ErrThrottled},
+		{code: "InternalServiceError"},
+	}
+	kinds := []error{provider.ErrAccessDenied, provider.ErrSecretNotFound, provider.ErrDecryptionFailed, provider.ErrThrottled}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.code, func(t *testing.T) {
+			apiErr := awserr.New(row.code, "some api err", nil)
+			for _, err := range []error{
+				WrapRequestError(apiErr),
+				WrapRequestError(awserr.NewRequestFailure(apiErr, 400, "7c6e4a52-1f0b-4e5f-9d0a-3e1b2c3d4e5f")),
+			} {
+				for _, kind := range kinds {
+					assert.Equal(t, kind == row.expectedKind, errors.Is(err, kind), "%s: %v", kind, err)
+				}
+				var awsErr awserr.Error
+				assert.True(t, errors.As(err, &awsErr))
+				assert.Equal(t, row.code, awsErr.Code())
+			}
+		})
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	errFailure := awserr.NewRequestFailure(awserr.New("ResourceNotFoundException", "secret not found", nil), 400, "2d8c0f1e-aaaa-bbbb-cccc-0123456789ab")
+	err := RefreshOnExpiredCredentials(nil, func() error {
+		return errFailure
+	})
+	assert.EqualError(t, err, "ResourceNotFoundException: secret not found (status code: 400, request id: 2d8c0f1e-aaaa-bbbb-cccc-0123456789ab)")
+	var requestErr *RequestError
+	assert.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, "2d8c0f1e-aaaa-bbbb-cccc-0123456789ab", requestErr.RequestID)
+	// the error of the SDK can still be inspected
+	var awsErr awserr.Error
+	assert.True(t, errors.As(err, &awsErr))
+	assert.Equal(t, "ResourceNotFoundException", awsErr.Code())
+}
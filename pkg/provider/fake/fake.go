@@ -16,6 +16,7 @@ package fake
 
 import (
 	"context"
+	"fmt"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -30,8 +31,11 @@ var _ provider.Provider = &Client{}
 type Client struct {
 	NewFn func(context.Context, esv1alpha1.GenericStore, client.Client,
 		string) (provider.SecretsClient, error)
-	GetSecretFn    func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error)
-	GetSecretMapFn func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error)
+	GetSecretFn     func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error)
+	GetSecretMapFn  func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error)
+	PinnedVersionFn func(esv1alpha1.ExternalSecretDataRemoteRef) bool
+	ExistsFn        func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (bool, error)
+	ValidateFn      func(context.Context) error
 }
 
 // New returns a fake provider/client.
@@ -43,6 +47,9 @@ func New() *Client {
 		GetSecretMapFn: func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
 			return nil, nil
 		},
+		ExistsFn: func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+			return true, nil
+		},
 	}
 
 	v.NewFn = func(context.Context, esv1alpha1.GenericStore, client.Client, string) (provider.SecretsClient, error) {
@@ -70,6 +77,19 @@ func (v *Client) WithGetSecret(secData []byte, err error) *Client {
 	return v
 }
 
+// WithGetSecretByKey returns the secret data stored under the requested key.
+// Unknown keys result in an error.
+func (v *Client) WithGetSecretByKey(secData map[string][]byte) *Client {
+	v.GetSecretFn = func(_ context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+		data, ok := secData[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("key %q does not exist", ref.Key)
+		}
+		return data, nil
+	}
+	return v
+}
+
 // GetSecretMap imeplements the provider.Provider interface.
 func (v *Client) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
 	return v.GetSecretMapFn(ctx, ref)
@@ -83,6 +103,48 @@ func (v *Client) WithGetSecretMap(secData map[string][]byte, err error) *Client
 	return v
 }
 
+// Exists implements the provider.Provider interface.
+func (v *Client) Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	return v.ExistsFn(ctx, ref)
+}
+
+// WithExists wraps the result of Exists.
+func (v *Client) WithExists(exists bool, err error) *Client {
+	v.ExistsFn = func(context.Context, esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+		return exists, err
+	}
+	return v
+}
+
+// IsPinnedVersion implements the provider.VersionPinner interface.
+// Versions are not pinned unless WithPinnedVersion is used.
+func (v *Client) IsPinnedVersion(ref esv1alpha1.ExternalSecretDataRemoteRef) bool {
+	return v.PinnedVersionFn != nil && v.PinnedVersionFn(ref)
+}
+
+// WithPinnedVersion wraps the function deciding whether a version is pinned.
+func (v *Client) WithPinnedVersion(f func(esv1alpha1.ExternalSecretDataRemoteRef) bool) *Client {
+	v.PinnedVersionFn = f
+	return v
+}
+
+// Validate implements the provider.Validator interface.
+// The store is valid unless WithValidate is used.
+func (v *Client) Validate(ctx context.Context) error {
+	if v.ValidateFn == nil {
+		return nil
+	}
+	return v.ValidateFn(ctx)
+}
+
+// WithValidate wraps the result of Validate.
+func (v *Client) WithValidate(err error) *Client {
+	v.ValidateFn = func(context.Context) error {
+		return err
+	}
+	return v
+}
+
 // WithNew wraps the fake provider factory function.
 func (v *Client) WithNew(f func(context.Context, esv1alpha1.GenericStore, client.Client,
 	string) (provider.SecretsClient, error)) *Client {
@@ -16,6 +16,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -35,4 +36,55 @@ type SecretsClient interface {
 
 	// GetSecretMap returns multiple k/v pairs from the provider
 	GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error)
+
+	// Exists checks whether the secret exists without fetching its value, if supported.
+	// A secret that does not exist is reported as false without error,
+	// a secret that can not be accessed results in an error wrapping ErrAccessDenied
+	Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error)
+}
+
+// ErrAccessDenied is wrapped by errors of providers that are not allowed to access a secret.
+var ErrAccessDenied = errors.New("access denied")
+
+// ErrPropertyNotFound is wrapped by errors of providers if the property of a remote ref
+// does not exist in the secret. Secrets that are no valid documents are reported otherwise.
+var ErrPropertyNotFound = errors.New("property not found")
+
+// ErrSecretNotFound is wrapped by errors of providers if the secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// ErrDecryptionFailed is wrapped by errors of providers that could not decrypt a secret,
+// e.g. because the credentials are not allowed to use its KMS key.
+var ErrDecryptionFailed = errors.New("provider could not decrypt secret")
+
+// ErrThrottled is wrapped by errors of providers that rejected a request because of rate limits.
+var ErrThrottled = errors.New("request throttled")
+
+// ErrDisallowedKMSKey is wrapped by errors of providers that refuse to fetch a secret
+// because it is not encrypted with a KMS key allowed by the store.
+var ErrDisallowedKMSKey = errors.New("secret is not encrypted with an allowed KMS key")
+
+// VersionPinner is implemented by SecretsClients that can tell whether a
+// remote ref selects an immutable version of a secret. Values of such refs
+// are cached by the ExternalSecret controller instead of fetching them again.
+type VersionPinner interface {
+	// IsPinnedVersion returns true if the value of ref can not change
+	IsPinnedVersion(ref esv1alpha1.ExternalSecretDataRemoteRef) bool
+}
+
+// Validator is implemented by SecretsClients that can check whether the store
+// configuration works, e.g. whether the credentials are accepted by the provider.
+// It is called periodically by the SecretStore controller, so it should be cheap.
+type Validator interface {
+	// Validate returns an error if the provider can not be used with the store
+	Validate(ctx context.Context) error
+}
+
+// AuthMethodReporter is implemented by SecretsClients that were created with one
+// of several authentication methods of the store. The SecretStore controller
+// records the method in the status of the store.
+type AuthMethodReporter interface {
+	// AuthMethod returns the name of the method, it is empty if the store
+	// does not configure multiple methods
+	AuthMethod() string
 }
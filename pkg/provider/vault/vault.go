@@ -60,8 +60,9 @@ const (
 	errGetKubeSA        = "cannot get Kubernetes service account %q: %w"
 	errGetKubeSASecrets = "cannot find secrets bound to service account: %q"
 
-	errGetKubeSecret = "cannot get Kubernetes secret %q: %w"
-	errSecretKeyFmt  = "cannot find secret data for key: %q"
+	errGetKubeSecret     = "cannot get Kubernetes secret %q: %w"
+	errSecretKeyFmt      = "cannot find secret data for key: %q"
+	errSecretPropertyFmt = "%w: cannot find secret data for key: %q"
 )
 
 type Client interface {
@@ -140,15 +141,53 @@ func (v *client) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDat
 	}
 	value, exists := data[ref.Property]
 	if !exists {
-		return nil, fmt.Errorf(errSecretKeyFmt, ref.Property)
+		return nil, fmt.Errorf(errSecretPropertyFmt, provider.ErrPropertyNotFound, ref.Property)
 	}
 	return value, nil
 }
 
+// IsPinnedVersion returns true if ref selects a version of a KV v2 secret.
+// Versions of KV v2 secrets are immutable.
+func (v *client) IsPinnedVersion(ref esv1alpha1.ExternalSecretDataRemoteRef) bool {
+	return v.store.Version == esv1alpha1.VaultKVStoreV2 && ref.Version != ""
+}
+
 func (v *client) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
 	return v.readSecret(ctx, ref.Key, ref.Version)
 }
 
+// Exists checks whether the secret exists. KV v2 secrets are checked with their metadata,
+// which does not include the value. KV v1 has no metadata, so the secret is read.
+func (v *client) Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	var err error
+	if v.store.Version == esv1alpha1.VaultKVStoreV2 {
+		metadataPath := fmt.Sprintf("/v1/%s/metadata/%s", strings.TrimSuffix(v.store.Path, "/data"), ref.Key)
+		var resp *vault.Response
+		resp, err = v.client.RawRequestWithContext(ctx, v.client.NewRequest(http.MethodGet, metadataPath))
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+		if err != nil {
+			err = fmt.Errorf(errReadSecret, err)
+		}
+	} else {
+		_, err = v.readSecret(ctx, ref.Key, ref.Version)
+	}
+	var respErr *vault.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.StatusCode {
+		case http.StatusNotFound:
+			return false, nil
+		case http.StatusForbidden:
+			return false, fmt.Errorf("%w: %s", provider.ErrAccessDenied, respErr.Error())
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (v *client) readSecret(ctx context.Context, path, version string) (map[string][]byte, error) {
 	kvPath := v.store.Path
 
@@ -33,6 +33,7 @@ import (
 
 	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
 	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+	"github.com/external-secrets/external-secrets/pkg/provider"
 	"github.com/external-secrets/external-secrets/pkg/provider/vault/fake"
 )
 
@@ -285,3 +286,95 @@ func TestGetSecretMap(t *testing.T) {
 		})
 	}
 }
+
+func TestExists(t *testing.T) {
+	errBoom := errors.New("boom")
+	kvV1 := func(s *esv1alpha1.SecretStore) {
+		s.Spec.Provider.Vault.Version = esv1alpha1.VaultKVStoreV1
+	}
+
+	type args struct {
+		store   *esv1alpha1.VaultProvider
+		vClient Client
+	}
+
+	type want struct {
+		exists bool
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Exists": {
+			reason: "Should return true if the metadata of the secret can be read.",
+			args: args{
+				store: makeSecretStore().Spec.Provider.Vault,
+				vClient: &fake.VaultClient{
+					MockNewRequest:            fake.NewMockNewRequestFn(&vault.Request{}),
+					MockRawRequestWithContext: fake.NewMockRawRequestWithContextFn(newVaultResponse(&vault.Secret{}), nil),
+				},
+			},
+			want: want{
+				exists: true,
+			},
+		},
+		"NotFound": {
+			reason: "Should return false without error if Vault responds with 404.",
+			args: args{
+				store: makeSecretStore().Spec.Provider.Vault,
+				vClient: &fake.VaultClient{
+					MockNewRequest:            fake.NewMockNewRequestFn(&vault.Request{}),
+					MockRawRequestWithContext: fake.NewMockRawRequestWithContextFn(nil, &vault.ResponseError{StatusCode: http.StatusNotFound}),
+				},
+			},
+			want: want{
+				exists: false,
+			},
+		},
+		"AccessDenied": {
+			reason: "Should return ErrAccessDenied if Vault responds with 403.",
+			args: args{
+				store: makeSecretStore().Spec.Provider.Vault,
+				vClient: &fake.VaultClient{
+					MockNewRequest:            fake.NewMockNewRequestFn(&vault.Request{}),
+					MockRawRequestWithContext: fake.NewMockRawRequestWithContextFn(nil, &vault.ResponseError{StatusCode: http.StatusForbidden}),
+				},
+			},
+			want: want{
+				err: provider.ErrAccessDenied,
+			},
+		},
+		"ReadSecretErrorKVv1": {
+			reason: "Should return error if vault client fails to read a KV v1 secret, which has no metadata.",
+			args: args{
+				store: makeSecretStore(kvV1).Spec.Provider.Vault,
+				vClient: &fake.VaultClient{
+					MockNewRequest:            fake.NewMockNewRequestFn(&vault.Request{}),
+					MockRawRequestWithContext: fake.NewMockRawRequestWithContextFn(nil, errBoom),
+				},
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			vStore := &client{
+				client: tc.args.vClient,
+				store:  tc.args.store,
+			}
+			exists, err := vStore.Exists(context.Background(), esv1alpha1.ExternalSecretDataRemoteRef{Key: "foo"})
+			if exists != tc.want.exists {
+				t.Errorf("\n%s\nvault.Exists(...): want %t, got %t", tc.reason, tc.want.exists, exists)
+			}
+			if !errors.Is(err, tc.want.err) {
+				t.Errorf("\n%s\nvault.Exists(...): want error %v, got %v", tc.reason, tc.want.err, err)
+			}
+		})
+	}
+}
@@ -18,7 +18,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
 	tpl "text/template"
 
@@ -44,12 +47,16 @@ var tplFuncs = tpl.FuncMap{
 	"toBytes":  toBytes,
 	"upper":    strings.ToUpper,
 	"lower":    strings.ToLower,
+
+	"dsn":        dsn,
+	"shellquote": shellquote,
 }
 
 const (
 	errParse                = "unable to parse template at key %s: %s"
 	errExecute              = "unable to execute template at key %s: %s"
 	errDecodePKCS12WithPass = "unable to decode pkcs12 with password: %s"
+	errInvalidPKCS12        = "unable to decode pkcs12, the data is not a valid pkcs12 archive: %s"
 	errConvertPrivKey       = "unable to convert pkcs12 private key: %s"
 	errDecodeCertWithPass   = "unable to decode pkcs12 certificate with password: %s"
 	errEncodePEMKey         = "unable to encode pem private key: %s"
@@ -57,6 +64,7 @@ const (
 	errDecodeBase64         = "unable to decode base64: %s"
 	errUnmarshalJSON        = "unable to unmarshal json: %s"
 	errMarshalJSON          = "unable to marshal json: %s"
+	errDSNDriver            = "unable to build dsn: unsupported driver %q"
 )
 
 // Execute renders the secret data as template. If an error occurs processing is stopped immediately.
@@ -81,7 +89,7 @@ func Execute(secret *corev1.Secret, data map[string][]byte) error {
 func pkcs12keyPass(pass string, input []byte) ([]byte, error) {
 	key, _, err := pkcs12.Decode(input, pass)
 	if err != nil {
-		return nil, fmt.Errorf(errDecodePKCS12WithPass, err)
+		return nil, pkcs12Error(errDecodePKCS12WithPass, err)
 	}
 	kb, err := pkcs8.ConvertPrivateKeyToPKCS8(key)
 	if err != nil {
@@ -97,11 +105,19 @@ func pkcs12key(input []byte) ([]byte, error) {
 func pkcs12certPass(pass string, input []byte) ([]byte, error) {
 	_, cert, err := pkcs12.Decode(input, pass)
 	if err != nil {
-		return nil, fmt.Errorf(errDecodeCertWithPass, err)
+		return nil, pkcs12Error(errDecodeCertWithPass, err)
 	}
 	return cert.Raw, nil
 }
 
+// pkcs12Error tells a wrong password apart from data that is not a pkcs12 archive at all.
+func pkcs12Error(format string, err error) error {
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return fmt.Errorf(format, err)
+	}
+	return fmt.Errorf(errInvalidPKCS12, err)
+}
+
 func pkcs12cert(input []byte) ([]byte, error) {
 	return pkcs12certPass("", input)
 }
@@ -163,3 +179,52 @@ func toString(in []byte) string {
 func toBytes(in string) []byte {
 	return []byte(in)
 }
+
+// dsn builds a database URL. User, password and database are escaped, so they
+// may contain characters like @, : or /. The port is optional.
+func dsn(driver string, host, port, user, password, database interface{}) (string, error) {
+	u := &url.URL{
+		User: url.UserPassword(dsnValue(user), dsnValue(password)),
+		Host: dsnValue(host),
+	}
+	if p := dsnValue(port); p != "" {
+		u.Host = net.JoinHostPort(u.Host, p)
+	}
+	switch driver {
+	case "postgres", "postgresql", "mysql":
+		u.Scheme = driver
+		u.Path = "/" + dsnValue(database)
+		u.RawPath = "/" + url.PathEscape(dsnValue(database))
+	case "sqlserver":
+		u.Scheme = driver
+		u.RawQuery = url.Values{"database": []string{dsnValue(database)}}.Encode()
+	default:
+		return "", fmt.Errorf(errDSNDriver, driver)
+	}
+	return u.String(), nil
+}
+
+// dsnValue converts secret values and values from fromJSON to strings.
+func dsnValue(in interface{}) string {
+	switch v := in.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// shellquote quotes secret values and values from fromJSON for POSIX shells.
+func shellquote(in interface{}) string {
+	return ShellQuote(dsnValue(in))
+}
+
+// ShellQuote encloses a value in single quotes, so a POSIX shell does not expand
+// $, backticks or whitespace. Single quotes of the value are written as '\''.
+func ShellQuote(in string) string {
+	return "'" + strings.ReplaceAll(in, "'", `'\''`) + "'"
+}
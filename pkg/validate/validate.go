@@ -0,0 +1,314 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validate runs an ExternalSecret through the reconcile pipeline
+// against a fake store, without a cluster or provider credentials.
+package validate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	esv1alpha1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1alpha1"
+	"github.com/external-secrets/external-secrets/pkg/controllers/externalsecret"
+	"github.com/external-secrets/external-secrets/pkg/mapformat"
+	"github.com/external-secrets/external-secrets/pkg/property"
+	"github.com/external-secrets/external-secrets/pkg/provider"
+)
+
+const (
+	defaultNamespace = "default"
+	redactedValue    = "<redacted>"
+
+	errReadFile                = "unable to read %s: %w"
+	errDecodeManifest          = "unable to decode manifest: %w"
+	errDecodeStore             = "unable to decode fake store: %w"
+	errMissingES               = "manifest does not contain an ExternalSecret"
+	errMultipleES              = "manifest must contain exactly one ExternalSecret"
+	errMissingFlags            = "both --external-secret and --store must be set"
+	errFakeStoreKey            = "key %q does not exist in fake store"
+	errFakeStoreProperty       = "%w: key %s does not exist in secret %s"
+	errFakeStorePropertyEngine = "unable to extract property %s from secret %s: %w"
+	errFakeStoreMap            = "unable to unmarshal secret %s: %w"
+	errReconcile               = "unable to reconcile ExternalSecret: %w"
+	errNotSynced               = "ExternalSecret could not be synced: %s"
+	errGetSecret               = "unable to get target secret: %w"
+)
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+var _ provider.Provider = &FakeStore{}
+
+// FakeStore defines the secrets served by the fake provider.
+type FakeStore struct {
+	// Data maps provider keys to their values.
+	Data map[string]string `json:"data"`
+}
+
+// Run executes the validate subcommand with the given command line arguments
+// and writes the resulting Secret to out.
+func Run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(out)
+	esPath := fs.String("external-secret", "", "path to a manifest containing the ExternalSecret and optional ConfigMaps it references")
+	storePath := fs.String("store", "", "path to the fake store definition")
+	showValues := fs.Bool("show-values", false, "print the values of the resulting Secret instead of redacting them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *esPath == "" || *storePath == "" {
+		return fmt.Errorf(errMissingFlags)
+	}
+
+	manifest, err := ioutil.ReadFile(*esPath)
+	if err != nil {
+		return fmt.Errorf(errReadFile, *esPath, err)
+	}
+	storeDef, err := ioutil.ReadFile(*storePath)
+	if err != nil {
+		return fmt.Errorf(errReadFile, *storePath, err)
+	}
+	var store FakeStore
+	if err := yaml.Unmarshal(storeDef, &store); err != nil {
+		return fmt.Errorf(errDecodeStore, err)
+	}
+
+	secret, err := Validate(context.Background(), manifest, &store)
+	if err != nil {
+		return err
+	}
+	return printSecret(secret, *showValues, out)
+}
+
+// Validate reconciles the ExternalSecret found in the manifest against the fake store
+// and returns the Secret that would be written to the cluster.
+func Validate(ctx context.Context, manifest []byte, store *FakeStore) (*corev1.Secret, error) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = esv1alpha1.AddToScheme(scheme)
+
+	objects, err := decodeManifest(scheme, manifest)
+	if err != nil {
+		return nil, err
+	}
+	es, err := findExternalSecret(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	// the reconciler uses the fake store instead of the provider of the stores
+	storeProvider := &esv1alpha1.SecretStoreProvider{
+		AWS: &esv1alpha1.AWSProvider{},
+	}
+
+	// all stores of the ExternalSecret serve the fake store,
+	// without a store it only renders templates from in-cluster values
+	stores := make(map[types.NamespacedName]bool)
+	for _, ref := range externalsecret.StoreRefs(es) {
+		storeMeta := metav1.ObjectMeta{Name: ref.Name}
+		if ref.Kind != esv1alpha1.ClusterSecretStoreKind {
+			storeMeta.Namespace = es.Namespace
+		}
+		key := types.NamespacedName{Name: storeMeta.Name, Namespace: storeMeta.Namespace}
+		if stores[key] {
+			continue
+		}
+		stores[key] = true
+		if ref.Kind == esv1alpha1.ClusterSecretStoreKind {
+			objects = append(objects, &esv1alpha1.ClusterSecretStore{
+				ObjectMeta: storeMeta,
+				Spec:       esv1alpha1.SecretStoreSpec{Provider: storeProvider},
+			})
+			continue
+		}
+		objects = append(objects, &esv1alpha1.SecretStore{
+			ObjectMeta: storeMeta,
+			Spec:       esv1alpha1.SecretStoreSpec{Provider: storeProvider},
+		})
+	}
+
+	kube := clientfake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	r := &externalsecret.Reconciler{
+		Client: kube,
+		Log:    ctrl.Log.WithName("validate"),
+		Scheme: scheme,
+		GetProvider: func(esv1alpha1.GenericStore) (provider.Provider, error) {
+			return store, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: es.Name, Namespace: es.Namespace}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		return nil, fmt.Errorf(errReconcile, err)
+	}
+
+	var synced esv1alpha1.ExternalSecret
+	if err := kube.Get(ctx, req.NamespacedName, &synced); err != nil {
+		return nil, fmt.Errorf(errReconcile, err)
+	}
+	cond := externalsecret.GetExternalSecretCondition(synced.Status, esv1alpha1.ExternalSecretReady)
+	if cond == nil || cond.Status != corev1.ConditionTrue {
+		msg := "no ready condition"
+		if cond != nil {
+			msg = cond.Message
+		}
+		return nil, fmt.Errorf(errNotSynced, msg)
+	}
+
+	var secret corev1.Secret
+	err = kube.Get(ctx, types.NamespacedName{Name: es.Spec.Target.Name, Namespace: es.Namespace}, &secret)
+	if err != nil {
+		return nil, fmt.Errorf(errGetSecret, err)
+	}
+	return &secret, nil
+}
+
+func decodeManifest(scheme *runtime.Scheme, manifest []byte) ([]client.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	var objects []client.Object
+	for _, doc := range documentSeparator.Split(string(manifest), -1) {
+		if len(bytes.TrimSpace([]byte(doc))) == 0 {
+			continue
+		}
+		obj, _, err := decoder.Decode([]byte(doc), nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf(errDecodeManifest, err)
+		}
+		cObj, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf(errDecodeManifest, fmt.Errorf("unsupported object %T", obj))
+		}
+		if cObj.GetNamespace() == "" {
+			cObj.SetNamespace(defaultNamespace)
+		}
+		objects = append(objects, cObj)
+	}
+	return objects, nil
+}
+
+func findExternalSecret(objects []client.Object) (*esv1alpha1.ExternalSecret, error) {
+	var found *esv1alpha1.ExternalSecret
+	for _, obj := range objects {
+		es, ok := obj.(*esv1alpha1.ExternalSecret)
+		if !ok {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf(errMultipleES)
+		}
+		found = es
+	}
+	if found == nil {
+		return nil, fmt.Errorf(errMissingES)
+	}
+	return found, nil
+}
+
+// NewClient implements the provider.Provider interface, all stores serve the fake store.
+func (s *FakeStore) NewClient(context.Context, esv1alpha1.GenericStore, client.Client, string) (provider.SecretsClient, error) {
+	return s, nil
+}
+
+// GetSecret implements the provider.SecretsClient interface.
+func (s *FakeStore) GetSecret(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) ([]byte, error) {
+	val, ok := s.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf(errFakeStoreKey, ref.Key)
+	}
+	if ref.Property == "" {
+		return []byte(val), nil
+	}
+	res, err := property.Get(ref.PropertyEngine, val, ref.Property)
+	if errors.Is(err, property.ErrNotFound) {
+		return nil, fmt.Errorf(errFakeStoreProperty, provider.ErrPropertyNotFound, ref.Property, ref.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(errFakeStorePropertyEngine, ref.Property, ref.Key, err)
+	}
+	return []byte(res), nil
+}
+
+// Exists implements the provider.SecretsClient interface.
+func (s *FakeStore) Exists(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (bool, error) {
+	_, ok := s.Data[ref.Key]
+	return ok, nil
+}
+
+// GetSecretMap implements the provider.SecretsClient interface.
+func (s *FakeStore) GetSecretMap(ctx context.Context, ref esv1alpha1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
+	data, err := s.GetSecret(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if !mapformat.IsJSON(ref.MapFormat) {
+		return mapformat.Parse(ref.MapFormat, data)
+	}
+	if ref.UnwrapJSONString {
+		data, err = mapformat.UnwrapJSONString(data)
+		if err != nil {
+			return nil, fmt.Errorf(errFakeStoreMap, ref.Key, err)
+		}
+	}
+	secretData, err := mapformat.ParseJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf(errFakeStoreMap, ref.Key, err)
+	}
+	return secretData, nil
+}
+
+// printSecret writes the secret as YAML manifest. Values are redacted unless showValues is set.
+func printSecret(secret *corev1.Secret, showValues bool, out io.Writer) error {
+	printed := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secret.Name,
+			Namespace:   secret.Namespace,
+			Labels:      secret.Labels,
+			Annotations: secret.Annotations,
+		},
+		Type:       secret.Type,
+		StringData: make(map[string]string, len(secret.Data)),
+	}
+	for k, v := range secret.Data {
+		printed.StringData[k] = redactedValue
+		if showValues {
+			printed.StringData[k] = string(v)
+		}
+	}
+	b, err := yaml.Marshal(printed)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
@@ -0,0 +1,335 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package validate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/schema"
+)
+
+const fakeStore = `
+data:
+  /grafana/user: admin
+  /grafana/db: '{"password": "s3cr3t", "host": "db.example.com"}'
+`
+
+const validManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: grafana-tpl
+data:
+  url: "postgres://{{ .user | toString }}@{{ .host | toString }}"
+---
+apiVersion: external-secrets.io/v1alpha1
+kind: ExternalSecret
+metadata:
+  name: grafana
+spec:
+  secretStoreRef:
+    name: aws
+  target:
+    name: grafana
+    template:
+      data:
+        # {{ .password | toString | upper }}
+        upperpassword: e3sgLnBhc3N3b3JkIHwgdG9TdHJpbmcgfCB1cHBlciB9fQ==
+      templateFrom:
+      - configMap:
+          name: grafana-tpl
+          items:
+          - key: url
+  data:
+  - secretKey: user
+    remoteRef:
+      key: /grafana/user
+  - secretKey: host
+    remoteRef:
+      key: /grafana/db
+      property: host
+  dataFrom:
+  - key: /grafana/db
+`
+
+const invalidPropertyManifest = `
+apiVersion: external-secrets.io/v1alpha1
+kind: ExternalSecret
+metadata:
+  name: grafana
+spec:
+  secretStoreRef:
+    name: aws
+  target:
+    name: grafana
+  data:
+  - secretKey: host
+    remoteRef:
+      key: /grafana/db
+      property: hostname
+`
+
+const invalidTemplateManifest = `
+apiVersion: external-secrets.io/v1alpha1
+kind: ExternalSecret
+metadata:
+  name: grafana
+spec:
+  secretStoreRef:
+    name: aws
+    kind: ClusterSecretStore
+  target:
+    name: grafana
+    template:
+      data:
+        # {{ .user | toString
+        config: e3sgLnVzZXIgfCB0b1N0cmluZyA=
+  data:
+  - secretKey: user
+    remoteRef:
+      key: /grafana/user
+`
+
+func writeFiles(t *testing.T, manifest string) (string, string) {
+	dir, err := ioutil.TempDir("", "validate")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	esPath := filepath.Join(dir, "es.yaml")
+	storePath := filepath.Join(dir, "store.yaml")
+	assert.Nil(t, ioutil.WriteFile(esPath, []byte(manifest), 0600))
+	assert.Nil(t, ioutil.WriteFile(storePath, []byte(fakeStore), 0600))
+	return esPath, storePath
+}
+
+func TestRunValid(t *testing.T) {
+	esPath, storePath := writeFiles(t, validManifest)
+
+	out := bytes.NewBuffer(nil)
+	err := Run([]string{"--external-secret", esPath, "--store", storePath}, out)
+	assert.Nil(t, err)
+	for _, key := range []string{"user:", "host:", "password:", "upperpassword:", "url:"} {
+		assert.Contains(t, out.String(), key)
+	}
+	assert.Contains(t, out.String(), redactedValue)
+	assert.NotContains(t, out.String(), "s3cr3t")
+
+	out.Reset()
+	err = Run([]string{"--external-secret", esPath, "--store", storePath, "--show-values"}, out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "upperpassword: S3CR3T")
+	assert.Contains(t, out.String(), "url: postgres://admin@db.example.com")
+	assert.NotContains(t, out.String(), redactedValue)
+}
+
+// the fake store is passed to the reconciler, the registered providers are not replaced.
+func TestRunKeepsProviders(t *testing.T) {
+	_, registered := schema.GetProviderByName("aws")
+	esPath, storePath := writeFiles(t, validManifest)
+	assert.Nil(t, Run([]string{"--external-secret", esPath, "--store", storePath}, bytes.NewBuffer(nil)))
+	_, registeredAfterRun := schema.GetProviderByName("aws")
+	assert.Equal(t, registered, registeredAfterRun)
+}
+
+func TestRunInvalid(t *testing.T) {
+	for _, row := range []struct {
+		manifest    string
+		expectError string
+	}{
+		{
+			manifest:    invalidPropertyManifest,
+			expectError: "key hostname does not exist in secret /grafana/db",
+		},
+		{
+			manifest:    invalidTemplateManifest,
+			expectError: "unable to parse template at key config",
+		},
+		{
+			manifest:    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n",
+			expectError: errMissingES,
+		},
+	} {
+		esPath, storePath := writeFiles(t, row.manifest)
+		err := Run([]string{"--external-secret", esPath, "--store", storePath}, ioutil.Discard)
+		if err == nil || !strings.Contains(err.Error(), row.expectError) {
+			t.Errorf("unexpected error: %v, expected: '%s'", err, row.expectError)
+		}
+	}
+}
+
+func TestRunMissingFlags(t *testing.T) {
+	err := Run([]string{"--store", "store.yaml"}, ioutil.Discard)
+	assert.EqualError(t, err, errMissingFlags)
+}
+
+const defaultManifest = `
+apiVersion: external-secrets.io/v1alpha1
+kind: ExternalSecret
+metadata:
+  name: grafana
+spec:
+  secretStoreRef:
+    name: aws
+  target:
+    name: grafana
+  data:
+  - secretKey: port
+    remoteRef:
+      key: %s
+      property: port
+    default: "5432"
+`
+
+func TestValidateDefault(t *testing.T) {
+	store := &FakeStore{Data: map[string]string{
+		"/grafana/db":      `{"host": "db.example.com", "port": "6432"}`,
+		"/grafana/db-dev":  `{"host": "localhost"}`,
+		"/grafana/db-text": `host=localhost`,
+	}}
+	for _, row := range []struct {
+		test         string
+		key          string
+		expectedPort string
+		expectError  string
+	}{
+		{
+			test:         "present property",
+			key:          "/grafana/db",
+			expectedPort: "6432",
+		},
+		{
+			test:         "missing property uses the default",
+			key:          "/grafana/db-dev",
+			expectedPort: "5432",
+		},
+		{
+			test:        "invalid json does not use the default",
+			key:         "/grafana/db-text",
+			expectError: "unable to extract property port from secret /grafana/db-text: value is not valid JSON",
+		},
+		{
+			test:        "missing secret does not use the default",
+			key:         "/grafana/other",
+			expectError: `key "/grafana/other" does not exist in fake store`,
+		},
+	} {
+		t.Run(row.test, func(t *testing.T) {
+			secret, err := Validate(context.Background(), []byte(fmt.Sprintf(defaultManifest, row.key)), store)
+			if row.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), row.expectError) {
+					t.Errorf("unexpected error: %v, expected: '%s'", err, row.expectError)
+				}
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, row.expectedPort, string(secret.Data["port"]))
+		})
+	}
+}
+
+const jsonKeyManifest = `
+apiVersion: external-secrets.io/v1alpha1
+kind: ExternalSecret
+metadata:
+  name: grafana
+spec:
+  secretStoreRef:
+    name: aws
+  target:
+    name: grafana
+  dataFrom:
+  - key: /grafana/config
+    jsonKey: config.json
+`
+
+func TestValidateJSONKey(t *testing.T) {
+	const expected = `{"db_host":"db.example.com","db_port":"5432","password":"p\"w","user":"admin"}`
+	for _, row := range []struct {
+		test   string
+		config string
+	}{
+		{
+			test:   "sorted source",
+			config: `{"db_host": "db.example.com", "db_port": "5432", "password": "p\"w", "user": "admin"}`,
+		},
+		{
+			test:   "unsorted source",
+			config: `{"user": "admin", "password": "p\"w", "db_port": "5432", "db_host": "db.example.com"}`,
+		},
+	} {
+		t.Run(row.test, func(t *testing.T) {
+			store := &FakeStore{Data: map[string]string{"/grafana/config": row.config}}
+			for i := 0; i < 10; i++ {
+				secret, err := Validate(context.Background(), []byte(jsonKeyManifest), store)
+				if !assert.Nil(t, err) {
+					return
+				}
+				assert.Equal(t, expected, string(secret.Data["config.json"]))
+			}
+		})
+	}
+}
+
+const entryStoreManifest = `
+apiVersion: external-secrets.io/v1alpha1
+kind: ExternalSecret
+metadata:
+  name: grafana
+spec:
+  target:
+    name: grafana
+  data:
+  - secretKey: user
+    remoteRef:
+      key: /grafana/user
+    secretStoreRef:
+      name: aws
+  - secretKey: password
+    remoteRef:
+      key: /grafana/password
+    secretStoreRef:
+      name: vault
+      kind: ClusterSecretStore
+  - secretKey: admin
+    remoteRef:
+      key: /grafana/admin
+    secretStoreRef:
+      name: aws
+`
+
+// the stores of data entries serve the fake store as well.
+func TestValidateEntryStores(t *testing.T) {
+	store := &FakeStore{Data: map[string]string{
+		"/grafana/user":     "grafana",
+		"/grafana/password": "secret",
+		"/grafana/admin":    "admin",
+	}}
+	secret, err := Validate(context.Background(), []byte(entryStoreManifest), store)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, map[string][]byte{
+		"user":     []byte("grafana"),
+		"password": []byte("secret"),
+		"admin":    []byte("admin"),
+	}, secret.Data)
+}